@@ -0,0 +1,336 @@
+// volca-convert - dx7/algorithm.go
+// John Simpson <jms1@jms1.net> 2022-10-05
+//
+// The 32 DX7 algorithms, i.e. which operators modulate which, and which
+// operator has the feedback loop.
+
+package dx7
+
+import (
+    "fmt"
+    "sort"
+    "strings"
+)
+
+///////////////////////////////////////////////////////////////////////////////
+//
+// One algorithm. Operator numbers start at 1.
+//
+// Each edge is { modulator , target }, meaning the modulator's output goes
+// into the target's input. Operators which aren't the modulator in any edge
+// are carriers, meaning their output is what you hear.
+//
+// Feedback is { from , to }. For most algorithms this is the same operator
+// feeding back into itself, but in algorithms 4 and 6 the loop runs from
+// one operator back up to the top of its stack.
+
+type Algorithm struct {
+    Number      int
+    Edges       [][2]int
+    Feedback    [2]int
+}
+
+var Algorithms = []Algorithm{
+    {  1 , [][2]int{ {2,1} , {4,3} , {5,4} , {6,5} } , [2]int{ 6 , 6 } } ,
+    {  2 , [][2]int{ {2,1} , {4,3} , {5,4} , {6,5} } , [2]int{ 2 , 2 } } ,
+    {  3 , [][2]int{ {2,1} , {3,2} , {5,4} , {6,5} } , [2]int{ 6 , 6 } } ,
+    {  4 , [][2]int{ {2,1} , {3,2} , {5,4} , {6,5} } , [2]int{ 4 , 6 } } ,
+    {  5 , [][2]int{ {2,1} , {4,3} , {6,5} } , [2]int{ 6 , 6 } } ,
+    {  6 , [][2]int{ {2,1} , {4,3} , {6,5} } , [2]int{ 5 , 6 } } ,
+    {  7 , [][2]int{ {2,1} , {4,3} , {5,3} , {6,5} } , [2]int{ 6 , 6 } } ,
+    {  8 , [][2]int{ {2,1} , {4,3} , {5,3} , {6,5} } , [2]int{ 4 , 4 } } ,
+    {  9 , [][2]int{ {2,1} , {4,3} , {5,3} , {6,5} } , [2]int{ 2 , 2 } } ,
+    { 10 , [][2]int{ {2,1} , {3,2} , {5,4} , {6,4} } , [2]int{ 3 , 3 } } ,
+    { 11 , [][2]int{ {2,1} , {3,2} , {5,4} , {6,4} } , [2]int{ 6 , 6 } } ,
+    { 12 , [][2]int{ {2,1} , {4,3} , {5,3} , {6,3} } , [2]int{ 2 , 2 } } ,
+    { 13 , [][2]int{ {2,1} , {4,3} , {5,3} , {6,3} } , [2]int{ 6 , 6 } } ,
+    { 14 , [][2]int{ {2,1} , {4,3} , {5,4} , {6,4} } , [2]int{ 6 , 6 } } ,
+    { 15 , [][2]int{ {2,1} , {4,3} , {5,4} , {6,4} } , [2]int{ 2 , 2 } } ,
+    { 16 , [][2]int{ {2,1} , {3,1} , {4,3} , {5,1} , {6,5} } , [2]int{ 6 , 6 } } ,
+    { 17 , [][2]int{ {2,1} , {3,1} , {4,3} , {5,1} , {6,5} } , [2]int{ 2 , 2 } } ,
+    { 18 , [][2]int{ {2,1} , {3,1} , {4,1} , {5,4} , {6,5} } , [2]int{ 3 , 3 } } ,
+    { 19 , [][2]int{ {2,1} , {3,2} , {6,4} , {6,5} } , [2]int{ 6 , 6 } } ,
+    { 20 , [][2]int{ {3,1} , {3,2} , {5,4} , {6,4} } , [2]int{ 3 , 3 } } ,
+    { 21 , [][2]int{ {3,1} , {3,2} , {6,4} , {6,5} } , [2]int{ 3 , 3 } } ,
+    { 22 , [][2]int{ {2,1} , {6,3} , {6,4} , {6,5} } , [2]int{ 6 , 6 } } ,
+    { 23 , [][2]int{ {3,2} , {6,4} , {6,5} } , [2]int{ 6 , 6 } } ,
+    { 24 , [][2]int{ {6,3} , {6,4} , {6,5} } , [2]int{ 6 , 6 } } ,
+    { 25 , [][2]int{ {6,4} , {6,5} } , [2]int{ 6 , 6 } } ,
+    { 26 , [][2]int{ {3,2} , {5,4} , {6,4} } , [2]int{ 6 , 6 } } ,
+    { 27 , [][2]int{ {3,2} , {5,4} , {6,4} } , [2]int{ 3 , 3 } } ,
+    { 28 , [][2]int{ {2,1} , {4,3} , {5,4} } , [2]int{ 5 , 5 } } ,
+    { 29 , [][2]int{ {4,3} , {6,5} } , [2]int{ 6 , 6 } } ,
+    { 30 , [][2]int{ {4,3} , {5,4} } , [2]int{ 5 , 5 } } ,
+    { 31 , [][2]int{ {6,5} } , [2]int{ 6 , 6 } } ,
+    { 32 , [][2]int{} , [2]int{ 6 , 6 } } ,
+}
+
+////////////////////////////////////////
+// Return the algorithm for a voice's ALGO value (0-31, which the DX7 shows
+// as 1-32), or nil if the value is out of range.
+
+func AlgorithmFor( algo byte ) *Algorithm {
+    if ( int( algo ) >= len( Algorithms ) ) {
+        return nil
+    }
+
+    return &Algorithms[algo]
+}
+
+///////////////////////////////////////////////////////////////////////////////
+//
+// Operators whose outputs feed into "op", in order
+
+func (a *Algorithm) modulators_of( op int ) []int {
+    var rv []int
+
+    for _ , e := range a.Edges {
+        if ( e[1] == op ) {
+            rv = append( rv , e[0] )
+        }
+    }
+    sort.Ints( rv )
+
+    return rv
+}
+
+////////////////////////////////////////
+// Return true if an operator is a carrier, meaning it doesn't modulate any
+// other operator.
+
+func (a *Algorithm) is_carrier( op int ) bool {
+    for _ , e := range a.Edges {
+        if ( e[0] == op ) {
+            return false
+        }
+    }
+
+    return true
+}
+
+///////////////////////////////////////////////////////////////////////////////
+//
+// Return the carriers (the operators you actually hear) or the modulators
+// for a voice's ALGO value (0-31, which the DX7 shows as 1-32). Operator
+// numbers start at 1. If the ALGO value is out of range, nil is returned.
+
+func Carriers( algo byte ) []int {
+    return algo_ops( algo , true )
+}
+
+func Modulators( algo byte ) []int {
+    return algo_ops( algo , false )
+}
+
+func algo_ops( algo byte , carriers bool ) []int {
+    a := AlgorithmFor( algo )
+    if ( a == nil ) {
+        return nil
+    }
+
+    rv := []int{}
+    for op := 1 ; op <= 6 ; op ++ {
+        if ( a.is_carrier( op ) == carriers ) {
+            rv = append( rv , op )
+        }
+    }
+
+    return rv
+}
+
+////////////////////////////////////////
+// Return "CARRIER" or "MODULATOR" for one of a voice's operators (starting
+// at 1), or "" if the voice's ALGO value is out of range.
+
+func OperatorRole( v *Voice , op int ) string {
+    a := AlgorithmFor( v.ALGO )
+    if ( a == nil ) {
+        return ""
+    } else if ( a.is_carrier( op ) ) {
+        return "CARRIER"
+    }
+
+    return "MODULATOR"
+}
+
+////////////////////////////////////////
+// How far above the carriers an operator is. Carriers are 0, and anything
+// else is one more than the highest operator it modulates.
+
+func (a *Algorithm) depth( op int ) int {
+    rv := 0
+
+    for _ , e := range a.Edges {
+        if ( e[0] == op ) {
+            if d := a.depth( e[1] ) + 1 ; d > rv {
+                rv = d
+            }
+        }
+    }
+
+    return rv
+}
+
+////////////////////////////////////////
+// Describe the carriers and feedback loop, i.e. "CARRIERS 1 3  FEEDBACK 6"
+
+func (a *Algorithm) Summary() string {
+    var carriers []string
+
+    for _ , op := range Carriers( byte( a.Number - 1 ) ) {
+        carriers = append( carriers , fmt.Sprintf( "%d" , op ) )
+    }
+
+    fb := fmt.Sprintf( "%d" , a.Feedback[0] )
+    if ( a.Feedback[0] != a.Feedback[1] ) {
+        fb = fmt.Sprintf( "%d->%d" , a.Feedback[0] , a.Feedback[1] )
+    }
+
+    return fmt.Sprintf( "CARRIERS %s  FEEDBACK %s" , strings.Join( carriers , " " ) , fb )
+}
+
+///////////////////////////////////////////////////////////////////////////////
+//
+// Draw the algorithm, with the carriers at the bottom joined by a line and
+// each modulator above the operator(s) it modulates. The operators in the
+// feedback loop are marked with '*'.
+//
+//         [6]*
+//          |
+//         [5]
+//          |
+//    [2]  [4]
+//     |    |
+//    [1]  [3]
+//     |    |
+//     +----+
+
+func (a *Algorithm) Diagram() []string {
+    var col     [7]int
+    var placed  [7]bool
+    var next    int
+
+    ////////////////////////////////////////
+    // Give each operator a column. Each carrier starts a new column, with
+    // its first modulator above it, and any other modulators to the right.
+
+    var place func( op int , c int )
+    place = func( op int , c int ) {
+        col[op]    = c
+        placed[op] = true
+        if ( c >= next ) {
+            next = c + 1
+        }
+
+        first := true
+        for _ , m := range a.modulators_of( op ) {
+            if ( placed[m] ) {
+                continue
+            }
+
+            if ( first ) {
+                place( m , c )
+                first = false
+            } else {
+                place( m , next )
+            }
+        }
+    }
+
+    for op := 1 ; op <= 6 ; op ++ {
+        if ( a.is_carrier( op ) && !placed[op] ) {
+            place( op , next )
+        }
+    }
+
+    ////////////////////////////////////////
+    // Each level of operators uses one row, with a row of lines below it
+
+    top := 0
+    for op := 1 ; op <= 6 ; op ++ {
+        if d := a.depth( op ) ; d > top {
+            top = d
+        }
+    }
+
+    grid := make( [][]byte , top * 2 + 2 )
+    for y := range grid {
+        grid[y] = []byte( strings.Repeat( " " , next * 5 ) )
+    }
+
+    // Draw a line character. Where two lines cross or join, use '+'.
+    draw := func( y int , x int , c byte ) {
+        if ( ( grid[y][x] != ' ' ) && ( grid[y][x] != c ) ) {
+            c = '+'
+        }
+        grid[y][x] = c
+    }
+
+    // Draw a horizontal line between two columns, with '+' at the ends
+    hline := func( y int , c1 int , c2 int ) {
+        if ( c1 > c2 ) {
+            c1 , c2 = c2 , c1
+        }
+        for x := c1 * 5 + 2 ; x < c2 * 5 + 1 ; x ++ {
+            draw( y , x , '-' )
+        }
+        draw( y , c1 * 5 + 1 , '+' )
+        draw( y , c2 * 5 + 1 , '+' )
+    }
+
+    for op := 1 ; op <= 6 ; op ++ {
+        y := ( top - a.depth( op ) ) * 2
+        x := col[op] * 5
+
+        copy( grid[y][x:] , fmt.Sprintf( "[%d]" , op ) )
+        if ( ( op == a.Feedback[0] ) || ( op == a.Feedback[1] ) ) {
+            grid[y][ x + 3 ] = '*'
+        }
+    }
+
+    ////////////////////////////////////////
+    // Lines from each modulator down to what it modulates. The line goes
+    // straight down from the modulator, then across (if needed) in the row
+    // just above the target.
+
+    for _ , e := range a.Edges {
+        m , t := e[0] , e[1]
+        ym := ( top - a.depth( m ) ) * 2
+        yt := ( top - a.depth( t ) ) * 2
+
+        for y := ym + 1 ; y < yt - 1 ; y ++ {
+            draw( y , col[m] * 5 + 1 , '|' )
+        }
+
+        if ( col[m] == col[t] ) {
+            draw( yt - 1 , col[m] * 5 + 1 , '|' )
+        } else {
+            hline( yt - 1 , col[m] , col[t] )
+        }
+    }
+
+    ////////////////////////////////////////
+    // Join the carriers together at the bottom
+
+    bottom := len( grid ) - 1
+    first  := -1
+    for op := 1 ; op <= 6 ; op ++ {
+        if ( a.is_carrier( op ) ) {
+            if ( first < 0 ) {
+                first = op
+                draw( bottom , col[op] * 5 + 1 , '|' )
+            } else {
+                hline( bottom , col[first] , col[op] )
+            }
+        }
+    }
+
+    ////////////////////////////////////////
+
+    var rv []string
+    for _ , row := range grid {
+        rv = append( rv , strings.TrimRight( string( row ) , " " ) )
+    }
+
+    return rv
+}
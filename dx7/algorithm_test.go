@@ -0,0 +1,81 @@
+// volca-convert - dx7/algorithm_test.go
+// John Simpson <jms1@jms1.net> 2022-10-05
+
+package dx7
+
+import (
+    "fmt"
+    "strings"
+    "testing"
+)
+
+func TestAlgorithms( t *testing.T ) {
+    var all string
+
+    for n , a := range Algorithms {
+        if ( a.Number != n + 1 ) {
+            t.Errorf( "algorithm %d is in position %d" , a.Number , n + 1 )
+        }
+
+        ////////////////////////////////////////
+        // On the DX7, a modulator always has a higher number than the
+        // operator it modulates.
+
+        for _ , e := range a.Edges {
+            if ( e[0] <= e[1] ) {
+                t.Errorf( "algorithm %d: %d -> %d goes the wrong way" , a.Number , e[0] , e[1] )
+            }
+        }
+
+        ////////////////////////////////////////
+        // Every operator is drawn exactly once
+
+        diagram := strings.Join( a.Diagram() , "\n" )
+        for op := 1 ; op <= 6 ; op ++ {
+            if c := strings.Count( diagram , fmt.Sprintf( "[%d]" , op ) ) ; c != 1 {
+                t.Errorf( "algorithm %d: OP%d drawn %d times" , a.Number , op , c )
+            }
+        }
+
+        all += fmt.Sprintf( "ALGORITHM %d  %s\n%s\n\n" , a.Number , a.Summary() , diagram )
+    }
+
+    check_golden( t , "algorithms.txt" , all )
+
+    ////////////////////////////////////////
+    // Carriers and modulators
+
+    roles := []struct {
+        algo        byte
+        carriers    string
+        modulators  string
+    }{
+        {  0 , "[1 3]"           , "[2 4 5 6]" } ,
+        {  4 , "[1 3 5]"         , "[2 4 6]" } ,
+        { 18 , "[1 4 5]"         , "[2 3 6]" } ,
+        { 31 , "[1 2 3 4 5 6]"   , "[]" } ,
+    }
+
+    for _ , r := range roles {
+        c := fmt.Sprint( Carriers( r.algo ) )
+        m := fmt.Sprint( Modulators( r.algo ) )
+        if ( ( c != r.carriers ) || ( m != r.modulators ) ) {
+            t.Errorf( "ALGO %d: carriers %s, modulators %s, expected %s and %s" ,
+                r.algo , c , m , r.carriers , r.modulators )
+        }
+    }
+
+    if ( Carriers( 32 ) != nil ) {
+        t.Errorf( "Carriers(32) should be nil" )
+    }
+
+    v := InitVoice()
+    v.ALGO = 4
+    if ( OperatorRole( &v , 5 ) != "CARRIER" ) || ( OperatorRole( &v , 6 ) != "MODULATOR" ) {
+        t.Errorf( "OperatorRole() is wrong for ALGO 4" )
+    }
+
+    if ( AlgorithmFor( 31 ).Number != 32 ) || ( AlgorithmFor( 32 ) != nil ) {
+        t.Errorf( "AlgorithmFor() doesn't match the ALGO values 0-31" )
+    }
+}
@@ -0,0 +1,190 @@
+// volca-convert - dx7/checksum.go
+// John Simpson <jms1@jms1.net> 2022-09-24
+//
+// Verify and repair the checksum and "end of message" marker of DX7 SYX
+// messages.
+//
+// A DX7 voice dump looks like this:
+//
+//     6 bytes      header (see SYX_h1 and SYX_h32)
+//     155 or 4096  voice data
+//     1 byte       checksum
+//     1 byte       F7 (end of SYSEX message)
+//
+// The checksum is the two's complement of the sum of the data bytes,
+// masked to seven bits.
+
+package dx7
+
+import (
+    "bytes"
+    "fmt"
+)
+
+///////////////////////////////////////////////////////////////////////////////
+//
+// Calculate the checksum for the data portion of a SYX message
+
+func syx_checksum( data []byte ) byte {
+    cs := 0
+    for _ , b := range data {
+        cs += int( b )
+    }
+
+    return byte( ( ^cs + 1 ) & 0x7F )
+}
+
+///////////////////////////////////////////////////////////////////////////////
+//
+// Return the size of the voice data in a message with the given header,
+// or -1 if the header isn't one we recognize. The device number (the low
+// four bits of the third byte) can be anything.
+
+func syx_data_size( head []byte ) int {
+    if ( ( len( head ) < 6 ) || ( ( head[2] & 0xF0 ) != 0x00 ) ) {
+        return -1
+    }
+
+    if ( bytes.Equal( head[0:2] , SYX_h1[0:2] ) && bytes.Equal( head[3:6] , SYX_h1[3:6] ) ) {
+        return 155
+    } else if ( bytes.Equal( head[0:2] , SYX_h32[0:2] ) && bytes.Equal( head[3:6] , SYX_h32[3:6] ) ) {
+        return 4096
+    }
+
+    return -1
+}
+
+///////////////////////////////////////////////////////////////////////////////
+//
+// Verify the checksum and end marker of a message whose voice data starts
+// at buf[6] and is "size" bytes long. The caller must make sure that buf
+// is at least size + 6 bytes long.
+//
+// If the message stops early (without a checksum, or without an end
+// marker), "after" is whatever follows it in the file. Only its first byte
+// (if any) is used, to say what was found instead.
+
+func check_syx( buf []byte , size int , after []byte ) error {
+    cs_loc := 6 + size
+
+    ////////////////////////////////////////
+    // Return the byte at "n", or the first byte after the message
+
+    found := func( n int ) ( byte , bool ) {
+        if ( n < len( buf ) ) {
+            return buf[n] , false
+        } else if ( len( after ) > 0 ) {
+            return after[0] , false
+        }
+        return 0 , true
+    }
+
+    expected := syx_checksum( buf[ 6:cs_loc ] )
+    if b , eof := found( cs_loc ) ; ( cs_loc >= len( buf ) ) || ( b != expected ) {
+        return &ChecksumError{
+            Offset      : cs_loc ,
+            Expected    : expected ,
+            Actual      : b ,
+            EOF         : eof ,
+        }
+    }
+
+    if b , eof := found( cs_loc + 1 ) ; ( cs_loc + 1 >= len( buf ) ) || ( b != 0xF7 ) {
+        return &ChecksumError{
+            Offset      : cs_loc + 1 ,
+            Expected    : 0xF7 ,
+            Actual      : b ,
+            Terminator  : true ,
+            EOF         : eof ,
+        }
+    }
+
+    return nil
+}
+
+///////////////////////////////////////////////////////////////////////////////
+//
+// Return a copy of a SYX file's contents with the checksum and end marker
+// of every DX7 voice dump corrected. The second return value is true if
+// anything was changed.
+//
+// In each voice dump, whatever follows the voice data (the old checksum
+// and end marker, if they were there at all) is replaced with the correct
+// checksum and an F7. Anything else in the file (other SYSEX messages, or
+// padding between messages) is kept as-is.
+
+func FixChecksum( data []byte ) ( []byte , bool , error ) {
+    var rv      []byte
+    var found   bool
+
+    pos := 0
+    for {
+        start := bytes.IndexByte( data[pos:] , 0xF0 )
+        if ( start < 0 ) {
+            break
+        }
+        start += pos
+
+        ////////////////////////////////////////
+        // Copy anything in front of the message
+
+        rv = append( rv , data[pos:start]... )
+
+        ////////////////////////////////////////
+        // Find the end of the message, the same way Decode() does
+
+        end := len( data )
+        for n := start + 1 ; n < len( data ) ; n ++ {
+            if ( data[n] >= 0x80 ) {
+                end = n
+                break
+            }
+        }
+
+        msg := data[start:end]
+        pos  = end
+        if ( ( end < len( data ) ) && ( data[end] == 0xF7 ) ) {
+            msg = data[start:(end+1)]
+            pos = end + 1
+        }
+
+        ////////////////////////////////////////
+        // Anything which isn't a DX7 voice dump is copied as-is
+
+        size := syx_data_size( msg )
+        if ( size < 0 ) {
+            rv = append( rv , msg... )
+            continue
+        }
+
+        found = true
+
+        if ( len( msg ) < 6 + size ) {
+            return nil , false , &ParseError{
+                Offset  : start + len( msg ) ,
+                Msg     : fmt.Sprintf( "voice dump is truncated, need %d bytes of voice data" ,
+                    size ) ,
+            }
+        }
+
+        ////////////////////////////////////////
+        // Copy the header and voice data, then add the correct checksum
+        // and end marker.
+
+        cs_loc := 6 + size
+
+        rv = append( rv , msg[ :cs_loc ]... )
+        rv = append( rv , syx_checksum( msg[ 6:cs_loc ] ) , 0xF7 )
+    }
+
+    if ( !found ) {
+        return nil , false , &ParseError{
+            Offset  : 0 ,
+            Msg     : "no DX7 voice dump found" ,
+        }
+    }
+
+    rv = append( rv , data[pos:]... )
+
+    return rv , !bytes.Equal( rv , data ) , nil
+}
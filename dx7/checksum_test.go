@@ -0,0 +1,79 @@
+// volca-convert - dx7/checksum_test.go
+// John Simpson <jms1@jms1.net> 2022-09-27
+
+package dx7
+
+import (
+    "bytes"
+    "testing"
+)
+
+func TestFixChecksum( t *testing.T ) {
+    one  := GenerateSYX155( test_voice( 1 ) )
+    bank := GenerateSYX128( test_bank( 32 ) )
+
+    ////////////////////////////////////////
+    // Build a file out of pieces
+
+    join := func( parts ...[]byte ) []byte {
+        var rv []byte
+        for _ , p := range parts {
+            rv = append( rv , p... )
+        }
+        return rv
+    }
+
+    bad_one := append( []byte{} , one... )
+    bad_one[161] ^= 0x01
+
+    bad_bank := append( []byte{} , bank... )
+    bad_bank[4102] ^= 0x01
+
+    other := []byte{ 0xF0 , 0x7E , 0x00 , 0x06 , 0x01 , 0xF7 }
+
+    tests := []struct {
+        name    string
+        data    []byte
+        want    []byte
+    } {
+        { "correct" , join( one , bank ) , join( one , bank ) } ,
+        { "bad checksums" , join( bad_one , other , bad_bank ) , join( one , other , bank ) } ,
+        { "missing F7, then another message" ,
+            join( one[:162] , bank[:4103] , other ) , join( one , bank , other ) } ,
+        { "missing checksum and F7" , join( one[:161] , bank ) , join( one , bank ) } ,
+        { "padding" , join( []byte{ 0 , 0 } , bad_one , []byte{ 0 } ) ,
+            join( []byte{ 0 , 0 } , one , []byte{ 0 } ) } ,
+    }
+
+    for _ , test := range tests {
+        got , changed , err := FixChecksum( test.data )
+        if ( err != nil ) {
+            t.Errorf( "%s: %v" , test.name , err )
+            continue
+        }
+
+        if ( !bytes.Equal( got , test.want ) ) {
+            t.Errorf( "%s: output is wrong" , test.name )
+        }
+
+        if ( changed != !bytes.Equal( test.data , test.want ) ) {
+            t.Errorf( "%s: changed=%v" , test.name , changed )
+        }
+
+        ////////////////////////////////////////
+        // The output can be read without any errors
+
+        if _ , err = SYXCodec.Decode( bytes.NewReader( got ) ) ; err != nil {
+            t.Errorf( "%s: reading the output: %v" , test.name , err )
+        }
+    }
+
+    ////////////////////////////////////////
+    // Files which can't be fixed
+
+    for _ , data := range [][]byte{ other , bank[:1000] } {
+        if _ , _ , err := FixChecksum( data ) ; err == nil {
+            t.Errorf( "no error for a %d-byte file" , len( data ) )
+        }
+    }
+}
@@ -0,0 +1,323 @@
+// volca-convert - dx7/codec.go
+// John Simpson <jms1@jms1.net> 2022-09-17
+//
+// Codec interface, and the registry of known file formats.
+//
+// Each file format is handled by a Codec, which registers itself (from an
+// init() function) when the package is loaded. Adding a new format only
+// involves writing a new Codec and registering it - the command line
+// options, file type detection, and the usage message all come from the
+// registry.
+
+package dx7
+
+import (
+    "bytes"
+    "errors"
+    "fmt"
+    "io"
+    "io/ioutil"
+    "os"
+    "path/filepath"
+    "strings"
+)
+
+///////////////////////////////////////////////////////////////////////////////
+//
+// Options which affect how a Codec writes its output
+
+type Options struct {
+    // Generate "simple" output. The exact meaning depends on the format.
+    Simple  bool
+
+    // MIDI channel (1-16) to put in SYX headers. 0 means use the device
+    // number stored in the Bank.
+    Channel int
+
+    // Voice used to fill 32-voice banks when there aren't enough voices.
+    // nil means use INIT VOICE.
+    Pad     *Voice
+
+    // TEXT: add a line to each group showing what the values mean, i.e.
+    // the operator's frequency, or note names instead of numbers.
+    VerboseValues bool
+
+    // TEXT: don't draw each voice's algorithm
+    NoDiagram bool
+
+    // JSON and CSV: show whether each operator is a carrier or a modulator
+    // (in TEXT this is always shown). This is ignored when reading.
+    Roles bool
+
+    // JSON and CSV: write names instead of numbers for the parameters which
+    // have them, i.e. "SINE" instead of 4. See Param.Symbol().
+    Symbolic bool
+}
+
+///////////////////////////////////////////////////////////////////////////////
+//
+// The Codec interface
+
+type Codec interface {
+    // Names the format is known by. The first one is the "official" name,
+    // the others are accepted as aliases (i.e. "TEXT" and "TXT").
+    Names() []string
+
+    // One-line description, for the usage message.
+    Description() string
+
+    // Filename extensions, including the ".", i.e. ".syx".
+    Extensions() []string
+
+    // Return true if the first few bytes of a file look like this format.
+    Sniff( head []byte ) bool
+
+    // Whether this format can be read and/or written.
+    CanDecode() bool
+    CanEncode() bool
+
+    // Whether the output is binary data (i.e. not safe to show on a
+    // terminal).
+    Binary() bool
+
+    Decode( r io.Reader ) ( Bank , error )
+    Encode( w io.Writer , bank Bank , opt Options ) error
+}
+
+////////////////////////////////////////
+// Codecs which can only recognize a file by something weak, such as its
+// size, implement this as well. Their Sniff() is only tried if no other
+// Codec matches, and only for files whose extension is in the list.
+
+type FallbackSniffer interface {
+    SniffExtensions() []string
+}
+
+////////////////////////////////////////
+// Returned by Decode() or Encode() for formats which can't do that.
+
+var ErrUnsupported = errors.New( "operation not supported for this format" )
+
+///////////////////////////////////////////////////////////////////////////////
+//
+// Registry
+
+var codecs []Codec
+
+////////////////////////////////////////
+// Add a Codec to the registry. Codecs are listed (and detected) in the
+// order they were registered.
+
+func Register( c Codec ) {
+    codecs = append( codecs , c )
+}
+
+////////////////////////////////////////
+// Return all registered Codecs
+
+func Codecs() []Codec {
+    return codecs
+}
+
+////////////////////////////////////////
+// Find a Codec by name (case-insensitive). Returns nil if not found.
+
+func CodecByName( name string ) Codec {
+    for _ , c := range codecs {
+        for _ , n := range c.Names() {
+            if ( strings.EqualFold( n , name ) ) {
+                return c
+            }
+        }
+    }
+
+    return nil
+}
+
+////////////////////////////////////////
+// Find a Codec based on a filename's extension (case-insensitive).
+// Returns nil if not found.
+
+func CodecForFile( filename string ) Codec {
+    ext := filepath.Ext( filename )
+    if ( ext == "" ) {
+        return nil
+    }
+
+    for _ , c := range codecs {
+        for _ , e := range c.Extensions() {
+            if ( strings.EqualFold( e , ext ) ) {
+                return c
+            }
+        }
+    }
+
+    return nil
+}
+
+////////////////////////////////////////
+// Find a Codec which is able to read a file starting with the given bytes.
+// Returns nil if nothing matches. Codecs which are a FallbackSniffer are
+// not tried, see SniffFile().
+//
+// The "head" should be the first SniffSize bytes of the file (or the
+// entire file, if it's smaller than that). Some formats can only be
+// recognized by their size, so callers should not pass fewer bytes.
+
+const SniffSize = 8192
+
+func SniffCodec( head []byte ) Codec {
+    for _ , c := range codecs {
+        if _ , weak := c.( FallbackSniffer ) ; weak {
+            continue
+        }
+
+        if ( c.CanDecode() && c.Sniff( head ) ) {
+            return c
+        }
+    }
+
+    return nil
+}
+
+////////////////////////////////////////
+// Same as SniffCodec(), but if nothing matches, also try the Codecs which
+// are a FallbackSniffer, if the filename has one of their extensions.
+
+func SniffFile( filename string , head []byte ) Codec {
+    if c := SniffCodec( head ) ; c != nil {
+        return c
+    }
+
+    ext := filepath.Ext( filename )
+
+    for _ , c := range codecs {
+        f , weak := c.( FallbackSniffer )
+        if ( !weak || !c.CanDecode() || ( ext == "" ) ) {
+            continue
+        }
+
+        for _ , e := range f.SniffExtensions() {
+            if ( strings.EqualFold( e , ext ) && c.Sniff( head ) ) {
+                return c
+            }
+        }
+    }
+
+    return nil
+}
+
+///////////////////////////////////////////////////////////////////////////////
+//
+// Open a file for reading. The filename "-" means STDIN.
+//
+// STDIN can only be read once, but DetectFile() and ReadFile() both need
+// to read it, so the first time it's opened the entire contents are read
+// into memory and kept for later.
+
+var stdin_data []byte
+var stdin_read bool
+
+func open_file( filename string ) ( io.ReadCloser , error ) {
+    if ( filename != "-" ) {
+        return os.Open( filename )
+    }
+
+    if ( !stdin_read ) {
+        data , err := ioutil.ReadAll( os.Stdin )
+        if ( err != nil ) {
+            return nil , err
+        }
+
+        stdin_data = data
+        stdin_read = true
+    }
+
+    return ioutil.NopCloser( bytes.NewReader( stdin_data ) ) , nil
+}
+
+////////////////////////////////////////
+// Name to use for a file in error messages
+
+func display_name( filename string ) string {
+    if ( filename == "-" ) {
+        return "(stdin)"
+    }
+
+    return filename
+}
+
+///////////////////////////////////////////////////////////////////////////////
+//
+// Detect what kind of file this is by looking at its contents. The filename
+// "-" means STDIN.
+
+func DetectFile( filename string ) ( Codec , error ) {
+    file , err := open_file( filename )
+    if ( err != nil ) {
+        return nil , err
+    }
+    defer file.Close()
+
+    head := make( []byte , SniffSize )
+    n , err := io.ReadFull( file , head )
+    if ( ( err != nil ) && ( err != io.ErrUnexpectedEOF ) && ( err != io.EOF ) ) {
+        return nil , err
+    }
+
+    return SniffFile( filename , head[:n] ) , nil
+}
+
+///////////////////////////////////////////////////////////////////////////////
+//
+// Read a file using a Codec. Any ParseError returned will have the
+// filename filled in. The filename "-" means STDIN.
+
+func ReadFile( filename string , c Codec ) ( Bank , error ) {
+    file , err := open_file( filename )
+    if ( err != nil ) {
+        return Bank{} , err
+    }
+    defer file.Close()
+
+    bank , err := c.Decode( file )
+    return bank , set_file( err , display_name( filename ) )
+}
+
+///////////////////////////////////////////////////////////////////////////////
+//
+// Write a file using a Codec. If the filename is empty or "-", the output
+// is written to STDOUT, exactly as the Codec generated it.
+//
+// The output is generated in memory first, so that a file isn't created
+// (or overwritten) if the bank can't be written in this format.
+
+func WriteFile( filename string , c Codec , bank Bank , opt Options ) error {
+    var buf bytes.Buffer
+
+    err := c.Encode( &buf , bank , opt )
+    if ( err != nil ) {
+        return err
+    }
+
+    if ( ( filename == "" ) || ( filename == "-" ) ) {
+        _ , err = os.Stdout.Write( buf.Bytes() )
+        return err
+    }
+
+    return os.WriteFile( filename , buf.Bytes() , 0644 )
+}
+
+///////////////////////////////////////////////////////////////////////////////
+//
+// Return a codec's names as a string for messages, i.e. "TEXT (TXT)"
+
+func CodecNames( c Codec ) string {
+    names := c.Names()
+
+    if ( len( names ) < 2 ) {
+        return names[0]
+    }
+
+    return fmt.Sprintf( "%s (%s)" , names[0] , strings.Join( names[1:] , ", " ) )
+}
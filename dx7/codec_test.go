@@ -0,0 +1,48 @@
+// volca-convert - dx7/codec_test.go
+// John Simpson <jms1@jms1.net> 2022-09-18
+
+package dx7
+
+import (
+    "bytes"
+    "testing"
+)
+
+func TestSniffFile( t *testing.T ) {
+    syx := GenerateSYX128( test_bank( 32 ) )
+    raw := syx[6:4102]
+
+    ////////////////////////////////////////
+    // 4096 bytes of text look exactly like a RAW bank
+
+    text := bytes.Repeat( []byte( "Some notes about the voices.\n" ) , 200 )[:4096]
+    json := append( []byte( "[" ) , bytes.Repeat( []byte( " " ) , 4094 )... )
+    json  = append( json , ']' )
+
+    tests := []struct {
+        filename    string
+        head        []byte
+        want        Codec
+    } {
+        { "bank.syx"    , syx   , SYXCodec } ,
+        { "bank.bin"    , syx   , SYXCodec } ,
+        { "bank.bin"    , raw   , RawCodec } ,
+        { "BANK.ROM"    , raw   , RawCodec } ,
+        { "bank"        , raw   , nil } ,
+        { "bank.dat"    , raw   , nil } ,
+        { "notes"       , text  , nil } ,
+        { "notes.txt"   , text  , nil } ,
+        { "voices"      , json  , JSONCodec } ,
+        { "voices.bin"  , json  , JSONCodec } ,
+    }
+
+    for _ , test := range tests {
+        if got := SniffFile( test.filename , test.head ) ; got != test.want {
+            name := "nil"
+            if ( got != nil ) {
+                name = got.Names()[0]
+            }
+            t.Errorf( "%s (%d bytes): detected as %s" , test.filename , len( test.head ) , name )
+        }
+    }
+}
@@ -0,0 +1,181 @@
+// volca-convert - dx7/data.go
+// John Simpson <jms1@jms1.net> 2022-08-27
+//
+// Global data and type definitions
+
+package dx7
+
+import (
+    "strings"
+)
+
+///////////////////////////////////////////////////////////////////////////////
+//
+// Type definitions
+
+////////////////////////////////////////
+// Voice data in memory.
+//
+// The field names are the same names the Volca FM/FM2 shows in its menus.
+// See params.go for the valid range of each field, and for where each one
+// is stored in a SYX file.
+
+type Operator struct {
+    EGR1    byte
+    EGR2    byte
+    EGR3    byte
+    EGR4    byte
+    EGL1    byte
+    EGL2    byte
+    EGL3    byte
+    EGL4    byte
+    LSBP    byte
+    LSLD    byte
+    LSRD    byte
+    LSLC    byte
+    LSRC    byte
+    ORS     byte
+    AMS     byte
+    KVS     byte
+    OLVL    byte
+    OSCM    byte
+    FREC    byte
+    FREF    byte
+    DETU    byte
+
+    // unused bits from the 32-voice SYX format
+    XX11    byte
+    XX13    byte
+    XX15    byte
+}
+
+type Global struct {
+    PTR1    byte
+    PTR2    byte
+    PTR3    byte
+    PTR4    byte
+    PTL1    byte
+    PTL2    byte
+    PTL3    byte
+    PTL4    byte
+    FDBK    byte
+    OKS     byte
+    LFOD    byte
+    LAMD    byte
+    LFOK    byte
+    LFOW    byte
+    MSP     byte
+    TRSP    byte
+}
+
+type Voice struct {
+    Name    string
+    ALGO    byte
+    LFOR    byte
+    LPMD    byte
+    OP      [6]Operator     // OP[0] is OP1
+    ALL     Global
+
+    // unused bits from the 32-voice SYX format
+    XX08    byte
+    XX09    byte
+}
+
+////////////////////////////////////////
+// A bank is an ordered list of voices. All of the ReadXXX() functions
+// return one of these, and all of the WriteXXX() functions write one.
+
+type Bank struct {
+    Voices  []Voice
+
+    // MIDI device number (0-15, which is the MIDI channel minus one) from
+    // the header of the SYX file the voices were read from. Formats which
+    // don't have a device number leave this as 0.
+    Device  byte
+}
+
+///////////////////////////////////////////////////////////////////////////////
+//
+// Global data
+
+////////////////////////////////////////
+// SYX file headers for 1- and 32-voice files. The low four bits of the
+// third byte are the device number, these headers use device 0 (MIDI
+// channel 1).
+
+var SYX_h1  = []byte{ 0xF0 , 0x43 , 0x00 , 0x00 , 0x01 , 0x1B }
+var SYX_h32 = []byte{ 0xF0 , 0x43 , 0x00 , 0x09 , 0x20 , 0x00 }
+
+///////////////////////////////////////////////////////////////////////////////
+//
+// Return a voice with every parameter set to the value used by the DX7's
+// "INIT VOICE". This is a simple sine wave, with only OP1 audible.
+
+func InitVoice() Voice {
+    var v Voice
+
+    for _ , p := range Params {
+        p.Set( &v , p.Default )
+    }
+
+    v.Name       = "INIT VOICE"
+    v.OP[0].OLVL = 99
+
+    return v
+}
+
+///////////////////////////////////////////////////////////////////////////////
+//
+// Return a copy of a bank with extra voices added to the end, so that it
+// contains (at least) "size" voices. If "pad" is nil, INIT VOICE is used.
+
+func PadBank( bank Bank , size int , pad *Voice ) Bank {
+    rv := bank
+    rv.Voices = append( []Voice{} , bank.Voices... )
+
+    p := InitVoice()
+    if ( pad != nil ) {
+        p = *pad
+    }
+
+    for len( rv.Voices ) < size {
+        rv.Voices = append( rv.Voices , p )
+    }
+
+    return rv
+}
+
+////////////////////////////////////////
+// Split a bank into banks of (at most) "size" voices each
+
+func SplitBank( bank Bank , size int ) []Bank {
+    var rv []Bank
+
+    for n := 0 ; n < len( bank.Voices ) ; n += size {
+        part := bank
+        end  := n + size
+        if ( end > len( bank.Voices ) ) {
+            end = len( bank.Voices )
+        }
+
+        part.Voices = append( []Voice{} , bank.Voices[n:end]... )
+        rv = append( rv , part )
+    }
+
+    return rv
+}
+
+///////////////////////////////////////////////////////////////////////////////
+//
+// Replace any characters which can't be stored in a SYX file (anything
+// outside of 20-7E) with spaces. The JSON and CSV writers do the same
+// thing, so names read from those files always come back the same way.
+
+func clean_name( name string ) string {
+    return strings.Map( func( c rune ) rune {
+        if ( ( c < 0x20 ) || ( c > 0x7E ) ) {
+            return ' '
+        }
+        return c
+    } , name )
+}
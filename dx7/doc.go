@@ -0,0 +1,44 @@
+// volca-convert - dx7/doc.go
+// John Simpson <jms1@jms1.net> 2022-09-10
+//
+// Package documentation
+
+// Package dx7 reads and writes Yamaha DX7 (and Korg Volca FM/FM2) voice
+// data. SYX, JSON, and CSV files can be read and written, HEX and RAW
+// files can be read, and TEXT and SVG files can be written.
+//
+// Every reader returns a Bank, and every writer takes a Bank, so programs
+// using this package can read, modify, and write voices without needing
+// any global state.
+//
+//     bank , err := dx7.ReadSYX( "input.syx" )
+//     if ( err != nil ) {
+//         return err
+//     }
+//     err = dx7.WriteFile( "output.txt" , dx7.TextCodec , bank ,
+//         dx7.Options{ VerboseValues: true } )
+//
+// Every format is written using a Codec's Encode() method (or WriteFile(),
+// which calls it), and the Options it's given. GenerateText(),
+// GenerateJSON(), GenerateCSV(), WriteText(), WriteJSON(), and WriteCSV()
+// are older shortcuts which only support Options.Simple, so they're
+// deprecated.
+//
+// Options.Roles adds each operator's role (carrier or modulator) to JSON
+// and CSV output. This is only for humans, it's ignored when the file is
+// read back.
+//
+// The JSON and CSV readers accept both the numeric and the symbolic form
+// (see Options.Symbolic and Param.Symbol()) of every value, so files
+// written either way can be read back without any Options.
+//
+// Readers and writers never exit the program. Problems with a file's
+// contents are returned as *ParseError, voices which can't be written in
+// a given format are returned as *EncodeError, and I/O errors are returned
+// as they come from the "os" package.
+//
+// Some problems, such as a bad SYX checksum, still leave the reader with
+// usable voices. In this case the voices are returned along with the
+// error, and Recoverable(err) returns true.
+
+package dx7
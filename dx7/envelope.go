@@ -0,0 +1,317 @@
+// volca-convert - dx7/envelope.go
+// John Simpson <jms1@jms1.net> 2022-10-06
+//
+// Simulate the DX7's envelope generators and keyboard level scaling, so
+// they can be drawn (see write_svg.go).
+//
+// The math here follows the "Music Synthesizer for Android" engine (msfa,
+// which is also what Dexed uses), which was worked out by comparing its
+// output with a real DX7. The envelopes are updated once per block of 64
+// samples at 44.1 kHz, the same as msfa.
+
+package dx7
+
+///////////////////////////////////////////////////////////////////////////////
+//
+// Tables and constants from msfa
+
+const (
+    sample_rate     = 44100.0
+    block_size      = 64
+    block_time      = block_size / sample_rate
+
+    // Stop simulating a stage after this many seconds. The slowest rates
+    // take several minutes, which isn't useful to draw.
+    stage_limit     = 20.0
+)
+
+var level_lut = []int{ 0 , 5 , 9 , 13 , 17 , 20 , 23 , 25 , 27 , 29 , 31 , 33 ,
+    35 , 37 , 39 , 41 , 42 , 43 , 45 , 46 }
+
+var pitch_env_rate = []int{
+    1 , 2 , 3 , 3 , 4 , 4 , 5 , 5 , 6 , 6 , 7 , 7 , 8 , 8 , 9 , 9 , 10 , 10 , 11 ,
+    11 , 12 , 12 , 13 , 13 , 14 , 14 , 15 , 16 , 16 , 17 , 18 , 18 , 19 , 20 ,
+    21 , 22 , 23 , 24 , 25 , 26 , 27 , 28 , 30 , 31 , 33 , 34 , 36 , 37 , 38 ,
+    39 , 41 , 42 , 44 , 46 , 47 , 49 , 51 , 53 , 54 , 56 , 58 , 60 , 62 , 64 ,
+    66 , 68 , 70 , 72 , 74 , 76 , 79 , 82 , 85 , 88 , 91 , 94 , 98 , 102 , 106 ,
+    110 , 115 , 120 , 125 , 130 , 135 , 141 , 147 , 153 , 159 , 165 , 171 ,
+    178 , 185 , 193 , 200 , 207 , 215 , 223 , 231 , 240 ,
+}
+
+var pitch_env_tab = []int{
+    -128 , -116 , -104 , -95 , -85 , -76 , -68 , -61 , -56 , -52 , -49 , -46 ,
+    -43 , -41 , -39 , -37 , -35 , -33 , -32 , -31 , -30 , -29 , -28 , -27 , -26 ,
+    -25 , -24 , -23 , -22 , -21 , -20 , -19 , -18 , -17 , -16 , -15 , -14 , -13 ,
+    -12 , -11 , -10 , -9 , -8 , -7 , -6 , -5 , -4 , -3 , -2 , -1 , 0 , 1 , 2 ,
+    3 , 4 , 5 , 6 , 7 , 8 , 9 , 10 , 11 , 12 , 13 , 14 , 15 , 16 , 17 , 18 , 19 ,
+    20 , 21 , 22 , 23 , 24 , 25 , 26 , 27 , 28 , 29 , 30 , 31 , 32 , 33 , 34 ,
+    35 , 38 , 40 , 43 , 46 , 49 , 53 , 58 , 65 , 73 , 82 , 92 , 103 , 115 , 127 ,
+}
+
+var exp_scale_data = []int{ 0 , 1 , 2 , 3 , 4 , 5 , 6 , 7 , 8 , 9 , 11 , 14 ,
+    16 , 19 , 23 , 27 , 33 , 39 , 47 , 56 , 66 , 80 , 94 , 110 , 126 , 142 , 158 ,
+    174 , 190 , 206 , 222 , 238 , 250 }
+
+////////////////////////////////////////
+// Convert a 0-99 level to the DX7's internal 0-127 scale
+
+func scale_out_level( level int ) int {
+    if ( level >= 20 ) {
+        return 28 + level
+    } else if ( level < 0 ) {
+        return 0
+    }
+
+    return level_lut[level]
+}
+
+////////////////////////////////////////
+// How much faster the envelope runs for a given note, because of the
+// operator's rate scaling (ORS)
+
+func scale_rate( note int , sensitivity int ) int {
+    x := note / 3 - 7
+    if ( x < 0 ) {
+        x = 0
+    } else if ( x > 31 ) {
+        x = 31
+    }
+
+    return ( sensitivity * x ) >> 3
+}
+
+///////////////////////////////////////////////////////////////////////////////
+//
+// Keyboard level scaling. Returns the change to the operator's output level
+// (on the 0-127 scale) for a note.
+
+func scale_curve( group int , depth int , curve int ) int {
+    var scale int
+
+    if ( ( curve == 0 ) || ( curve == 3 ) ) {
+        scale = ( group * depth * 329 ) >> 12
+    } else {
+        if ( group > len( exp_scale_data ) - 1 ) {
+            group = len( exp_scale_data ) - 1
+        }
+        scale = ( exp_scale_data[group] * depth * 329 ) >> 15
+    }
+
+    if ( curve < 2 ) {
+        scale = -scale
+    }
+
+    return scale
+}
+
+func scale_level( o *Operator , note int ) int {
+    offset := note - int( o.LSBP ) - 17
+
+    if ( offset >= 0 ) {
+        return scale_curve( ( offset + 1 ) / 3 , int( o.LSRD ) , int( o.LSRC ) )
+    }
+
+    return scale_curve( -( offset - 1 ) / 3 , int( o.LSLD ) , int( o.LSLC ) )
+}
+
+////////////////////////////////////////
+// An operator's output level (0-127) for a note, after keyboard scaling
+
+func ScaledLevel( o *Operator , note int ) int {
+    rv := scale_out_level( int( o.OLVL ) ) + scale_level( o , note )
+    if ( rv > 127 ) {
+        rv = 127
+    } else if ( rv < 0 ) {
+        rv = 0
+    }
+
+    return rv
+}
+
+///////////////////////////////////////////////////////////////////////////////
+//
+// Envelope simulation
+
+type EnvPoint struct {
+    Time    float64     // seconds since the key was pressed (or released)
+    Level   float64     // operator: 0-4096 (log scale), pitch: semitones
+}
+
+////////////////////////////////////////
+// A simulated envelope, for a note which is held until the envelope reaches
+// its third level (L3), then released.
+
+type Envelope struct {
+    Held        []EnvPoint  // while the key is held
+    Released    []EnvPoint  // after the key is released (times start at 0)
+
+    // true if a stage took so long it was cut off (see stage_limit)
+    HeldCut     bool
+    ReleasedCut bool
+}
+
+////////////////////////////////////////
+// The state of one envelope while it's being simulated. Levels are in
+// msfa's fixed-point units.
+
+type envelope struct {
+    level   int64
+    target  int64
+    inc     int64
+    rising  bool
+    attack  bool    // operator EGs rise along a curve, pitch EGs don't
+}
+
+////////////////////////////////////////
+// Run one stage until it reaches its target level. Returns the points
+// (starting with where the stage started), the time it ended, and true if
+// it was cut off at the time limit.
+
+func (e *envelope) run( t float64 , scale float64 ) ( []EnvPoint , float64 , bool ) {
+    rv := []EnvPoint{ { t , float64( e.level ) * scale } }
+
+    limit := stage_limit / block_time
+
+    for n := 0 ; n < int( limit ) ; n ++ {
+        done := false
+
+        if ( e.rising ) {
+            if ( e.attack ) {
+                if ( e.level < ( 1716 << 16 ) ) {
+                    e.level = 1716 << 16
+                }
+                e.level += ( ( ( 17 << 24 ) - e.level ) >> 24 ) * e.inc
+            } else {
+                e.level += e.inc
+            }
+            done = ( e.level >= e.target )
+        } else {
+            e.level -= e.inc
+            done = ( e.level <= e.target )
+        }
+
+        if ( done ) {
+            e.level = e.target
+        }
+
+        t += block_time
+        rv = append( rv , EnvPoint{ t , float64( e.level ) * scale } )
+
+        if ( done ) {
+            return thin_points( rv ) , t , false
+        }
+    }
+
+    return thin_points( rv ) , t , true
+}
+
+////////////////////////////////////////
+// Keep at most about 100 points from a stage, always including the last
+
+func thin_points( p []EnvPoint ) []EnvPoint {
+    step := len( p ) / 100 + 1
+    if ( step == 1 ) {
+        return p
+    }
+
+    var rv []EnvPoint
+    for n := 0 ; n < len( p ) - 1 ; n += step {
+        rv = append( rv , p[n] )
+    }
+
+    return append( rv , p[ len( p ) - 1 ] )
+}
+
+///////////////////////////////////////////////////////////////////////////////
+//
+// Simulate an operator's EG for a note. The output level is treated as 99,
+// so every operator's EG uses the same scale.
+
+func OperatorEnvelope( o *Operator , note int ) Envelope {
+    rates  := []int{ int( o.EGR1 ) , int( o.EGR2 ) , int( o.EGR3 ) , int( o.EGR4 ) }
+    levels := []int{ int( o.EGL1 ) , int( o.EGL2 ) , int( o.EGL3 ) , int( o.EGL4 ) }
+
+    outlevel     := scale_out_level( 99 ) << 5
+    rate_scaling := scale_rate( note , int( o.ORS ) )
+
+    e := envelope{ attack: true }
+
+    advance := func( ix int ) {
+        actual := scale_out_level( levels[ix] ) >> 1
+        actual  = ( actual << 6 ) + outlevel - 4256
+        if ( actual < 16 ) {
+            actual = 16
+        }
+
+        e.target = int64( actual ) << 16
+        e.rising = ( e.target > e.level )
+
+        qrate := ( ( rates[ix] * 41 ) >> 6 ) + rate_scaling
+        if ( qrate > 63 ) {
+            qrate = 63
+        }
+        e.inc = int64( 4 + ( qrate & 3 ) ) << uint( 2 + 6 + ( qrate >> 2 ) )
+    }
+
+    return run_envelope( &e , advance , 1.0 / 65536 )
+}
+
+////////////////////////////////////////
+// Same as OperatorEnvelope(), but for the pitch EG. Levels are in
+// semitones (0 = no change).
+
+func PitchEnvelope( v *Voice ) Envelope {
+    rates  := []byte{ v.ALL.PTR1 , v.ALL.PTR2 , v.ALL.PTR3 , v.ALL.PTR4 }
+    levels := []byte{ v.ALL.PTL1 , v.ALL.PTL2 , v.ALL.PTL3 , v.ALL.PTL4 }
+
+    // msfa's "unit_", the smallest change per block
+    unit_f := block_size * ( 1 << 24 ) / ( 21.3 * sample_rate )
+    unit   := int64( unit_f + 0.5 )
+
+    tab := func( n byte ) int64 {
+        if ( int( n ) >= len( pitch_env_tab ) ) {
+            n = byte( len( pitch_env_tab ) - 1 )
+        }
+        return int64( pitch_env_tab[n] ) << 19
+    }
+
+    rate := func( n byte ) int64 {
+        if ( int( n ) >= len( pitch_env_rate ) ) {
+            n = byte( len( pitch_env_rate ) - 1 )
+        }
+        return int64( pitch_env_rate[n] )
+    }
+
+    e := envelope{ level: tab( levels[3] ) }
+
+    advance := func( ix int ) {
+        e.target = tab( levels[ix] )
+        e.rising = ( e.target > e.level )
+        e.inc    = rate( rates[ix] ) * unit
+    }
+
+    return run_envelope( &e , advance , 12.0 / ( 1 << 24 ) )
+}
+
+////////////////////////////////////////
+// Run stages 1-3 (key held), then stage 4 (key released). "advance" sets
+// up the envelope for a stage, and "scale" converts levels for the points.
+
+func run_envelope( e *envelope , advance func( int ) , scale float64 ) Envelope {
+    var rv Envelope
+
+    t := 0.0
+    for ix := 0 ; ix < 3 ; ix ++ {
+        advance( ix )
+
+        p , end , cut := e.run( t , scale )
+        rv.Held    = append( rv.Held , p... )
+        rv.HeldCut = rv.HeldCut || cut
+        t          = end
+    }
+
+    advance( 3 )
+    rv.Released , _ , rv.ReleasedCut = e.run( 0 , scale )
+
+    return rv
+}
@@ -0,0 +1,125 @@
+// volca-convert - dx7/envelope_test.go
+// John Simpson <jms1@jms1.net> 2022-10-06
+
+package dx7
+
+import (
+    "bytes"
+    "encoding/xml"
+    "io"
+    "strings"
+    "testing"
+)
+
+func TestOperatorEnvelope( t *testing.T ) {
+    ////////////////////////////////////////
+    // INIT VOICE: straight up to full level, then straight down when the
+    // key is released
+
+    v := InitVoice()
+    env := OperatorEnvelope( &v.OP[0] , 60 )
+    held , released := env.Held , env.Released
+
+    if ( env.HeldCut || env.ReleasedCut ) {
+        t.Errorf( "INIT VOICE envelope was cut off" )
+    }
+
+    if end := held[ len( held ) - 1 ] ; ( end.Level != 3840 ) || ( end.Time > 0.05 ) {
+        t.Errorf( "INIT VOICE: held at %v, expected level 3840 within 0.05s" , end )
+    }
+
+    if end := released[ len( released ) - 1 ] ; ( end.Level != 16 ) || ( end.Time > 0.05 ) {
+        t.Errorf( "INIT VOICE: released to %v, expected level 16 within 0.05s" , end )
+    }
+
+    ////////////////////////////////////////
+    // Lower rates take longer
+
+    o    := v.OP[0]
+    last := 0.0
+
+    for r := 99 ; r >= 30 ; r -= 10 {
+        o.EGR1 , o.EGR2 , o.EGR3 , o.EGR4 = 99 , byte( r ) , 99 , 99
+        o.EGL1 , o.EGL2 , o.EGL3 , o.EGL4 = 99 , 0 , 0 , 0
+
+        held := OperatorEnvelope( &o , 60 ).Held
+        end := held[ len( held ) - 1 ].Time
+        if ( end <= last ) {
+            t.Errorf( "decay rate %d took %.3fs, which isn't longer than the rate above it" , r , end )
+        }
+        last = end
+    }
+
+    ////////////////////////////////////////
+    // Rate 0 takes minutes, so it's cut off
+
+    o.EGR2 = 0
+    if ( !OperatorEnvelope( &o , 60 ).HeldCut ) {
+        t.Errorf( "rate 0 wasn't cut off" )
+    }
+}
+
+func TestPitchEnvelope( t *testing.T ) {
+    v := InitVoice()
+    env := PitchEnvelope( &v )
+
+    for _ , p := range append( env.Held , env.Released... ) {
+        if ( p.Level != 0 ) {
+            t.Fatalf( "INIT VOICE pitch EG isn't flat: %v" , p )
+        }
+    }
+
+    v.ALL.PTL1 , v.ALL.PTL4 = 99 , 0
+    env = PitchEnvelope( &v )
+
+    if ( env.Held[0].Level != -48 ) || ( env.Released[ len( env.Released ) - 1 ].Level != -48 ) {
+        t.Errorf( "PTL4 0 should start and end 4 octaves down" )
+    }
+}
+
+func TestScaledLevel( t *testing.T ) {
+    v := InitVoice()
+    o := &v.OP[0]
+
+    for note := 21 ; note <= 120 ; note ++ {
+        if ( ScaledLevel( o , note ) != 127 ) {
+            t.Fatalf( "INIT VOICE note %d: level %d, expected 127" , note , ScaledLevel( o , note ) )
+        }
+    }
+
+    ////////////////////////////////////////
+    // -LIN on the left gets quieter going down, +EXP on the right can't go
+    // above 127
+
+    o.OLVL , o.LSLD , o.LSLC , o.LSRD , o.LSRC = 80 , 99 , 0 , 99 , 2
+
+    if ( ScaledLevel( o , 21 ) >= ScaledLevel( o , 40 ) ) || ( ScaledLevel( o , 40 ) >= ScaledLevel( o , 60 ) ) {
+        t.Errorf( "-LIN left curve doesn't get quieter going down" )
+    }
+
+    if ( ScaledLevel( o , 120 ) != 127 ) {
+        t.Errorf( "+EXP right curve: level %d at C8, expected 127" , ScaledLevel( o , 120 ) )
+    }
+}
+
+func TestSVG( t *testing.T ) {
+    bank := golden_bank()
+    svg  := GenerateSVG( bank )
+
+    ////////////////////////////////////////
+    // Make sure it's well-formed XML
+
+    d := xml.NewDecoder( bytes.NewReader( []byte( svg ) ) )
+    for {
+        _ , err := d.Token()
+        if ( err == io.EOF ) {
+            break
+        } else if ( err != nil ) {
+            t.Fatalf( "SVG is not valid XML: %v" , err )
+        }
+    }
+
+    if n := strings.Count( svg , "<g id=\"voice-" ) ; n != len( bank.Voices ) {
+        t.Errorf( "SVG has %d voices, expected %d" , n , len( bank.Voices ) )
+    }
+}
@@ -0,0 +1,238 @@
+// volca-convert - dx7/errors.go
+// John Simpson <jms1@jms1.net> 2022-09-10
+//
+// Error types returned by the readers and writers.
+//
+// Problems opening, reading, or writing files are returned as-is from the
+// "os" package (normally as *fs.PathError), so callers can tell them apart
+// from problems with the *contents* of a file.
+
+package dx7
+
+import (
+    "fmt"
+    "strings"
+)
+
+///////////////////////////////////////////////////////////////////////////////
+//
+// ParseError - the contents of an input file could not be understood.
+//
+// Fields which don't apply to a particular error are left at their zero
+// value, except Offset, which is -1 when it isn't known.
+
+type ParseError struct {
+    File    string  // filename, if known
+    Offset  int     // byte offset within the file (-1 = unknown)
+    Line    int     // line number in a text file (starting at 1)
+    Row     int     // CSV row number (starting at 1)
+    Column  int     // CSV or text column number (starting at 1)
+    Param   string  // parameter name, i.e. "OP3.EGR1"
+    Msg     string  // what went wrong
+}
+
+func (e *ParseError) Error() string {
+    var where []string
+
+    if ( e.File != "" ) {
+        where = append( where , fmt.Sprintf( "\"%s\"" , e.File ) )
+    }
+
+    if ( e.Offset >= 0 ) {
+        where = append( where , fmt.Sprintf( "byte %d" , e.Offset ) )
+    }
+
+    if ( e.Line > 0 ) {
+        where = append( where , fmt.Sprintf( "line %d" , e.Line ) )
+    }
+
+    if ( e.Row > 0 ) {
+        where = append( where , fmt.Sprintf( "row %d" , e.Row ) )
+    }
+
+    if ( e.Column > 0 ) {
+        where = append( where , fmt.Sprintf( "col %d" , e.Column ) )
+    }
+
+    if ( e.Param != "" ) {
+        where = append( where , e.Param )
+    }
+
+    if ( len( where ) < 1 ) {
+        return e.Msg
+    }
+
+    return strings.Join( where , " " ) + ": " + e.Msg
+}
+
+////////////////////////////////////////
+// Fill in the filename of a ParseError, if that's what err is. ReadFile()
+// calls this so that the codecs and parse functions don't need to know
+// the filename.
+
+func set_file( err error , filename string ) error {
+    switch e := err.( type ) {
+        case *ParseError:
+            e.File = filename
+        case *ChecksumError:
+            e.File = filename
+        case *TruncatedError:
+            e.File = filename
+        case *ValueError:
+            e.File = filename
+        case ErrorList:
+            for _ , x := range e {
+                set_file( x , filename )
+            }
+    }
+
+    return err
+}
+
+///////////////////////////////////////////////////////////////////////////////
+//
+// ChecksumError - a SYX message has the wrong checksum, or is missing the
+// F7 "end of message" marker.
+//
+// When a reader returns this, the voices it read are returned as well, so
+// the caller can decide to use them anyway.
+
+type ChecksumError struct {
+    File        string  // filename, if known
+    Offset      int     // byte offset of the checksum (or end marker)
+    Expected    byte
+    Actual      byte
+    Terminator  bool    // true = problem is the end marker, not the checksum
+    EOF         bool    // true = the file ended where the byte should be
+}
+
+func (e *ChecksumError) Error() string {
+    var where string
+
+    if ( e.File != "" ) {
+        where = fmt.Sprintf( "\"%s\" " , e.File )
+    }
+
+    what := "bad checksum"
+    if ( e.Terminator ) {
+        what = "missing end of message marker"
+    }
+
+    found := fmt.Sprintf( "%02X" , e.Actual )
+    if ( e.EOF ) {
+        found = "end of file"
+    }
+
+    return fmt.Sprintf( "%sbyte %d: %s: expected %02X, found %s" ,
+        where , e.Offset , what , e.Expected , found )
+}
+
+///////////////////////////////////////////////////////////////////////////////
+//
+// TruncatedError - a SYX message ended before all of its voice data was
+// read. Any voices which were complete are returned along with this.
+
+type TruncatedError struct {
+    File        string  // filename, if known
+    Offset      int     // byte offset where the message ended
+    Voices      int     // number of complete voices recovered
+    Expected    int     // number of voices the message should contain
+}
+
+func (e *TruncatedError) Error() string {
+    var where string
+
+    if ( e.File != "" ) {
+        where = fmt.Sprintf( "\"%s\" " , e.File )
+    }
+
+    return fmt.Sprintf( "%sbyte %d: %d-voice dump is truncated, recovered %d of %d voices" ,
+        where , e.Offset , e.Expected , e.Voices , e.Expected )
+}
+
+///////////////////////////////////////////////////////////////////////////////
+//
+// ValueError - a parameter in a voice is missing, unknown, or has a value
+// outside of its range.
+//
+// The voices are returned along with this error. Missing or unreadable
+// values are set to the parameter's default, out-of-range values are set
+// to the closest valid value, and unknown parameters are ignored.
+
+type ValueError struct {
+    File    string  // filename, if known
+    Voice   int     // voice number (starting at 1)
+    Name    string  // voice name
+    Param   string  // parameter name, i.e. "OP2.DETU"
+    Value   string  // the value from the file, if there was one
+    Msg     string  // what's wrong with it
+}
+
+func (e *ValueError) Error() string {
+    var where string
+
+    if ( e.File != "" ) {
+        where = fmt.Sprintf( "\"%s\" " , e.File )
+    }
+
+    where += fmt.Sprintf( "voice %d \"%s\" %s" , e.Voice ,
+        strings.TrimRight( e.Name , " " ) , e.Param )
+
+    if ( e.Value != "" ) {
+        where += "=" + e.Value
+    }
+
+    return where + ": " + e.Msg
+}
+
+///////////////////////////////////////////////////////////////////////////////
+//
+// ErrorList - more than one problem was found in the same file. Readers
+// only return this when every problem in the list is recoverable.
+
+type ErrorList []error
+
+func (e ErrorList) Error() string {
+    var msgs []string
+
+    for _ , x := range e {
+        msgs = append( msgs , x.Error() )
+    }
+
+    return strings.Join( msgs , "\n" )
+}
+
+///////////////////////////////////////////////////////////////////////////////
+//
+// Return true if err is a problem which a reader can work around, meaning
+// the Bank returned along with it contains every voice which could be read.
+// Callers can choose to use the voices anyway (with a warning).
+
+func Recoverable( err error ) bool {
+    switch e := err.( type ) {
+        case *ChecksumError , *TruncatedError , *ValueError:
+            return true
+        case ErrorList:
+            for _ , x := range e {
+                if ( !Recoverable( x ) ) {
+                    return false
+                }
+            }
+            return len( e ) > 0
+    }
+
+    return false
+}
+
+///////////////////////////////////////////////////////////////////////////////
+//
+// EncodeError - a bank cannot be written in the requested format.
+
+type EncodeError struct {
+    Format  string  // "SYX", "JSON", etc.
+    Msg     string  // what went wrong
+}
+
+func (e *EncodeError) Error() string {
+    return fmt.Sprintf( "cannot write %s: %s" , e.Format , e.Msg )
+}
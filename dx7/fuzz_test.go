@@ -0,0 +1,140 @@
+// volca-convert - dx7/fuzz_test.go
+// John Simpson <jms1@jms1.net> 2022-09-25
+//
+// Fuzz targets for the readers. Each one makes sure that the reader never
+// panics, and that anything it reads successfully can be written in the
+// same format and read back as exactly the same voices.
+//
+// The seed corpus is in testdata/fuzz. To run a fuzzer:
+//
+//     go test -fuzz=FuzzSYX -fuzztime=1m ./dx7
+
+package dx7
+
+import (
+    "bytes"
+    "testing"
+)
+
+///////////////////////////////////////////////////////////////////////////////
+//
+// Decode, encode, and decode again.
+//
+// SYX files can't always hold the voices exactly as they were read. For
+// example, a truncated 32-voice dump with only one complete voice has to be
+// written as a single voice, which has no room for the unused bits. For
+// these formats ("lossy" = true) the bank is written and read once first,
+// and that has to survive another round trip exactly.
+
+func fuzz_round_trip( t *testing.T , c Codec , data []byte , lossy bool ) {
+    bank , err := c.Decode( bytes.NewReader( data ) )
+    if ( ( err != nil ) && !Recoverable( err ) ) {
+        return
+    }
+
+    if ( lossy ) {
+        var buf bytes.Buffer
+        if ( c.Encode( &buf , bank , Options{} ) != nil ) {
+            return
+        }
+
+        bank , err = c.Decode( bytes.NewReader( buf.Bytes() ) )
+        if ( err != nil ) {
+            t.Fatalf( "reading back: %v" , err )
+        }
+    }
+
+    for _ , simple := range []bool{ false , true } {
+        // simple CSV has no header, so it can't be read back
+        if ( ( c == CSVCodec ) && simple ) {
+            continue
+        }
+
+        var buf bytes.Buffer
+        err = c.Encode( &buf , bank , Options{ Simple: simple } )
+        if ( err != nil ) {
+            // some formats can only hold certain numbers of voices
+            if _ , ok := err.( *EncodeError ) ; ok {
+                return
+            }
+            t.Fatalf( "Encode: %v" , err )
+        }
+
+        bank2 , err := c.Decode( bytes.NewReader( buf.Bytes() ) )
+        if ( err != nil ) {
+            t.Fatalf( "reading back (simple=%v): %v\n%q" , simple , err , buf.Bytes() )
+        }
+
+        if ( len( bank2.Voices ) != len( bank.Voices ) ) {
+            t.Fatalf( "read %d voices, read back %d" , len( bank.Voices ) , len( bank2.Voices ) )
+        }
+
+        for n := range bank.Voices {
+            if ( bank.Voices[n] != bank2.Voices[n] ) {
+                t.Fatalf( "voice %d changed (simple=%v):\n  %+v\n  %+v" ,
+                    n + 1 , simple , bank.Voices[n] , bank2.Voices[n] )
+            }
+        }
+    }
+}
+
+////////////////////////////////////////
+// Formats which can only be read (HEX and RAW) are checked by writing
+// whatever they read as SYX, which has to survive a round trip.
+
+func fuzz_read_only( t *testing.T , c Codec , data []byte ) {
+    bank , err := c.Decode( bytes.NewReader( data ) )
+    if ( ( err != nil ) && !Recoverable( err ) ) {
+        return
+    }
+
+    var buf bytes.Buffer
+    if ( SYXCodec.Encode( &buf , bank , Options{} ) != nil ) {
+        return
+    }
+
+    fuzz_round_trip( t , SYXCodec , buf.Bytes() , true )
+}
+
+///////////////////////////////////////////////////////////////////////////////
+
+func FuzzSYX( f *testing.F ) {
+    f.Fuzz( func( t *testing.T , data []byte ) {
+        fuzz_round_trip( t , SYXCodec , data , true )
+    } )
+}
+
+func FuzzHex( f *testing.F ) {
+    f.Fuzz( func( t *testing.T , data []byte ) {
+        fuzz_read_only( t , HexCodec , data )
+    } )
+}
+
+func FuzzRaw( f *testing.F ) {
+    f.Fuzz( func( t *testing.T , data []byte ) {
+        fuzz_read_only( t , RawCodec , data )
+    } )
+}
+
+func FuzzJSON( f *testing.F ) {
+    f.Fuzz( func( t *testing.T , data []byte ) {
+        fuzz_round_trip( t , JSONCodec , data , false )
+    } )
+}
+
+func FuzzCSV( f *testing.F ) {
+    f.Fuzz( func( t *testing.T , data []byte ) {
+        fuzz_round_trip( t , CSVCodec , data , false )
+    } )
+}
+
+////////////////////////////////////////
+// The voice parsers are also called directly, with whatever size of data
+// the caller has.
+
+func FuzzParseSYX( f *testing.F ) {
+    f.Fuzz( func( t *testing.T , data []byte ) {
+        ParseSYX155( data )
+        ParseSYX128( data )
+    } )
+}
@@ -0,0 +1,58 @@
+// volca-convert - dx7/golden_test.go
+// John Simpson <jms1@jms1.net> 2022-09-25
+//
+// Make sure the TEXT, CSV, and JSON writers generate exactly the same
+// output they always have.
+
+package dx7
+
+import (
+    "bytes"
+    "testing"
+)
+
+func golden_bank() Bank {
+    bank := test_bank( 3 )
+    bank.Voices = append( bank.Voices , InitVoice() )
+    return bank
+}
+
+////////////////////////////////////////
+// Write the golden bank using a Codec, the way programs using the package
+// would
+
+func golden_encode( t *testing.T , c Codec , opt Options ) string {
+    t.Helper()
+
+    var buf bytes.Buffer
+    if err := c.Encode( &buf , golden_bank() , opt ) ; err != nil {
+        t.Fatalf( "%s: %v" , c.Names()[0] , err )
+    }
+
+    return buf.String()
+}
+
+func TestGoldenText( t *testing.T ) {
+    check_golden( t , "bank.txt" , GenerateText( golden_bank() , true ) )
+    check_golden( t , "bank-simple.txt" , GenerateText( golden_bank() , false ) )
+    check_golden( t , "bank-values.txt" ,
+        golden_encode( t , TextCodec , Options{ VerboseValues: true } ) )
+}
+
+func TestGoldenCSV( t *testing.T ) {
+    check_golden( t , "bank.csv" , GenerateCSV( golden_bank() , true ) )
+    check_golden( t , "bank-simple.csv" , GenerateCSV( golden_bank() , false ) )
+    check_golden( t , "bank-symbolic.csv" ,
+        golden_encode( t , CSVCodec , Options{ Symbolic: true } ) )
+    check_golden( t , "bank-roles.csv" ,
+        golden_encode( t , CSVCodec , Options{ Roles: true } ) )
+}
+
+func TestGoldenJSON( t *testing.T ) {
+    check_golden( t , "bank.json" , GenerateJSON( golden_bank() , true ) )
+    check_golden( t , "bank-simple.json" , GenerateJSON( golden_bank() , false ) )
+    check_golden( t , "bank-symbolic.json" ,
+        golden_encode( t , JSONCodec , Options{ Symbolic: true } ) )
+    check_golden( t , "bank-roles.json" ,
+        golden_encode( t , JSONCodec , Options{ Roles: true } ) )
+}
@@ -0,0 +1,153 @@
+// volca-convert - dx7/helpers_test.go
+// John Simpson <jms1@jms1.net> 2022-09-25
+//
+// Helpers shared by the tests.
+//
+// The voices used by the tests are generated here rather than stored in
+// files, so that no copyrighted patches end up in the repo. The values are
+// "random", but always the same, so that golden output files can be used.
+
+package dx7
+
+import (
+    "flag"
+    "fmt"
+    "os"
+    "path/filepath"
+    "testing"
+)
+
+var update = flag.Bool( "update" , false , "rewrite golden files in testdata/golden" )
+
+///////////////////////////////////////////////////////////////////////////////
+//
+// Generate a voice with every parameter set to a value within its range.
+// The same seed always generates the same voice.
+
+func test_voice( seed int ) Voice {
+    var v Voice
+
+    x := uint32( seed ) * 2654435761 + 1
+
+    for _ , p := range Params {
+        if ( p.Unused ) {
+            continue
+        }
+
+        x = x * 1103515245 + 12345
+        p.Set( &v , p.Min + byte( ( x >> 16 ) % uint32( p.Max - p.Min + 1 ) ) )
+    }
+
+    ////////////////////////////////////////
+    // Names are padded to 10 characters, the same as they would be after
+    // reading them from a SYX file. Some of them contain characters which
+    // need to be escaped in JSON or CSV.
+
+    switch ( seed % 4 ) {
+        case 1:
+            v.Name = fmt.Sprintf( "SAY \"%02d\"  " , seed )
+        case 2:
+            v.Name = fmt.Sprintf( "BACK\\%02d,  " , seed )
+        default:
+            v.Name = fmt.Sprintf( "TEST %02d   " , seed )
+    }
+    v.Name = v.Name[:name_len]
+
+    return v
+}
+
+////////////////////////////////////////
+// Voices with every parameter at its minimum or maximum value
+
+func test_voice_limit( max bool ) Voice {
+    var v Voice
+
+    for _ , p := range Params {
+        if ( p.Unused ) {
+            continue
+        }
+
+        if ( max ) {
+            p.Set( &v , p.Max )
+        } else {
+            p.Set( &v , p.Min )
+        }
+    }
+
+    v.Name = "~~~~~~~~~~"
+    if ( !max ) {
+        v.Name = "          "
+    }
+
+    return v
+}
+
+////////////////////////////////////////
+// A bank with "n" generated voices
+
+func test_bank( n int ) Bank {
+    var bank Bank
+
+    for i := 0 ; i < n ; i ++ {
+        bank.Voices = append( bank.Voices , test_voice( i + 1 ) )
+    }
+
+    return bank
+}
+
+////////////////////////////////////////
+// The SYX files used by the tests: one voice, 32 voices, and 32 voices
+// which include the min/max voices.
+
+func test_syx_files() map[string][]byte {
+    limits := test_bank( 32 )
+    limits.Voices[0]  = test_voice_limit( false )
+    limits.Voices[31] = test_voice_limit( true )
+    limits.Voices[15] = InitVoice()
+
+    ////////////////////////////////////////
+    // Some voices with the unused bits set
+
+    junk := test_bank( 32 )
+    for n := 0 ; n < 32 ; n += 3 {
+        for i , p := range raw_params() {
+            p.Set( &junk.Voices[n] , byte( n + i ) & p.Mask )
+        }
+    }
+
+    return map[string][]byte{
+        "one"       : GenerateSYX155( test_voice( 1 ) ) ,
+        "junk"      : GenerateSYX128( junk ) ,
+        "bank"      : GenerateSYX128( test_bank( 32 ) ) ,
+        "limits"    : GenerateSYX128( limits ) ,
+        "one-limit" : GenerateSYX155( test_voice_limit( true ) ) ,
+    }
+}
+
+///////////////////////////////////////////////////////////////////////////////
+//
+// Compare output against a file in testdata/golden. If the test is run
+// with "-update", the file is rewritten instead.
+
+func check_golden( t *testing.T , name string , got string ) {
+    t.Helper()
+
+    path := filepath.Join( "testdata" , "golden" , name )
+
+    if ( *update ) {
+        err := os.WriteFile( path , []byte( got ) , 0644 )
+        if ( err != nil ) {
+            t.Fatalf( "writing %s: %v" , path , err )
+        }
+        return
+    }
+
+    want , err := os.ReadFile( path )
+    if ( err != nil ) {
+        t.Fatalf( "reading %s: %v (run 'go test -update' to create it)" , path , err )
+    }
+
+    if ( got != string( want ) ) {
+        t.Errorf( "output does not match %s (run 'go test -update' if the change is intended)" , path )
+    }
+}
@@ -0,0 +1,90 @@
+// volca-convert - dx7/lint.go
+// John Simpson <jms1@jms1.net> 2022-09-28
+//
+// Check voices for values the DX7 or Volca FM/FM2 can't use.
+//
+// Most file formats can hold values which are outside of a parameter's
+// range (i.e. "FDBK" = 12). When these are written to a 32-voice SYX file,
+// the extra bits are masked off, so the voice doesn't sound the way the
+// file says it should. Lint() finds these before that happens.
+
+package dx7
+
+import (
+    "fmt"
+    "strings"
+)
+
+///////////////////////////////////////////////////////////////////////////////
+//
+// Characters which the Volca FM/FM2 can show in a voice name. The display
+// can't show lower case letters, and the DX7 itself shows "\" as a yen
+// sign and "~" as an arrow, so those are best avoided as well.
+
+const VolcaChars = " !\"#$%&'()*+,-./0123456789:;<=>?@" +
+    "ABCDEFGHIJKLMNOPQRSTUVWXYZ[]^_"
+
+///////////////////////////////////////////////////////////////////////////////
+//
+// Check every voice in a bank. Returns a list of problems, which is empty
+// if everything is fine.
+
+func Lint( bank Bank ) []*ValueError {
+    var rv []*ValueError
+
+    for n := range bank.Voices {
+        rv = append( rv , LintVoice( &bank.Voices[n] , n + 1 )... )
+    }
+
+    return rv
+}
+
+////////////////////////////////////////
+// Check one voice. "num" is the voice number, for the messages.
+
+func LintVoice( v *Voice , num int ) []*ValueError {
+    var rv []*ValueError
+
+    problem := func( param string , value string , msg string ) {
+        rv = append( rv , &ValueError{
+            Voice   : num ,
+            Name    : v.Name ,
+            Param   : param ,
+            Value   : value ,
+            Msg     : msg ,
+        } )
+    }
+
+    ////////////////////////////////////////
+    // Name
+
+    if ( len( v.Name ) > name_len ) {
+        problem( "NAME" , "" , fmt.Sprintf( "longer than %d characters, the rest will be lost" ,
+            name_len ) )
+    }
+
+    var bad []string
+    for _ , c := range v.Name {
+        q := fmt.Sprintf( "%q" , string( c ) )
+        if ( !strings.ContainsRune( VolcaChars , c ) && !contains( bad , q ) ) {
+            bad = append( bad , q )
+        }
+    }
+
+    if ( len( bad ) > 0 ) {
+        problem( "NAME" , "" , "the Volca can't show " + strings.Join( bad , " " ) )
+    }
+
+    ////////////////////////////////////////
+    // Parameters
+
+    for _ , p := range file_params() {
+        n := p.Get( v )
+        if ( ( n < p.Min ) || ( n > p.Max ) ) {
+            problem( p.Name , fmt.Sprintf( "%d" , n ) ,
+                p.range_msg() )
+        }
+    }
+
+    return rv
+}
@@ -0,0 +1,60 @@
+// volca-convert - dx7/lint_test.go
+// John Simpson <jms1@jms1.net> 2022-09-28
+
+package dx7
+
+import (
+    "fmt"
+    "testing"
+)
+
+func TestLint( t *testing.T ) {
+    ////////////////////////////////////////
+    // Generated voices always have valid values (some of their names
+    // have a "\" in them, which the Volca can't show)
+
+    for _ , p := range Lint( test_bank( 32 ) ) {
+        if ( p.Param != "NAME" ) {
+            t.Errorf( "unexpected problem: %v" , p )
+        }
+    }
+
+    ////////////////////////////////////////
+    // Every parameter one past its maximum
+
+    v := InitVoice()
+    v.Name = "brass 1\\"
+    for _ , p := range file_params() {
+        p.Set( &v , p.Max + 1 )
+    }
+
+    want := []string{
+        `voice 3 "brass 1\" NAME: the Volca can't show "b" "r" "a" "s" "\\"` ,
+    }
+    for _ , p := range file_params() {
+        want = append( want , ( &ValueError{ Voice: 3 , Name: v.Name , Param: p.Name ,
+            Value: fmt.Sprintf( "%d" , p.Max + 1 ) ,
+            Msg: p.range_msg() } ).Error() )
+    }
+
+    got := LintVoice( &v , 3 )
+    if ( len( got ) != len( want ) ) {
+        t.Fatalf( "got %d problems, expected %d" , len( got ) , len( want ) )
+    }
+
+    for n := range got {
+        if ( got[n].Error() != want[n] ) {
+            t.Errorf( "got:\n%s\nexpected:\n%s" , got[n] , want[n] )
+        }
+    }
+
+    ////////////////////////////////////////
+    // Long names
+
+    v = InitVoice()
+    v.Name = "ELEVEN CHAR"
+    got = LintVoice( &v , 1 )
+    if ( ( len( got ) != 1 ) || ( got[0].Param != "NAME" ) ) {
+        t.Errorf( "long name not reported: %v" , got )
+    }
+}
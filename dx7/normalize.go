@@ -0,0 +1,132 @@
+// volca-convert - dx7/normalize.go
+// John Simpson <jms1@jms1.net> 2022-09-29
+//
+// Fix voices so that every value is something the DX7 can use.
+
+package dx7
+
+import (
+    "fmt"
+    "strings"
+)
+
+///////////////////////////////////////////////////////////////////////////////
+//
+// One change made by Normalize()
+
+type Change struct {
+    Voice   int     // voice number (starting at 1)
+    Name    string  // voice name, before it was changed
+    Param   string  // parameter name, i.e. "OP2.DETU"
+    Old     string  // value before the change
+    New     string  // value after the change
+}
+
+func (c Change) String() string {
+    return fmt.Sprintf( "voice %d \"%s\" %s: %s -> %s" , c.Voice ,
+        strings.TrimRight( c.Name , " " ) , c.Param , c.Old , c.New )
+}
+
+///////////////////////////////////////////////////////////////////////////////
+//
+// Normalize every voice in a bank. Returns a list of the changes made.
+
+func NormalizeBank( bank *Bank ) []Change {
+    var rv []Change
+
+    for n := range bank.Voices {
+        rv = append( rv , Normalize( &bank.Voices[n] , n + 1 )... )
+    }
+
+    return rv
+}
+
+////////////////////////////////////////
+// Normalize one voice. "num" is the voice number, for the list of changes.
+//
+// - Values outside of a parameter's range are set to the closest valid
+//   value.
+// - The unused bits from the 32-voice SYX format are set to zero.
+// - The name is made exactly 10 characters long, by adding spaces or
+//   removing characters from the end. Characters which the Volca can't
+//   show (see VolcaChars) are replaced, see volca_name().
+
+func Normalize( v *Voice , num int ) []Change {
+    var rv []Change
+
+    name := v.Name
+
+    change := func( param string , old string , new string ) {
+        rv = append( rv , Change{
+            Voice   : num ,
+            Name    : name ,
+            Param   : param ,
+            Old     : old ,
+            New     : new ,
+        } )
+    }
+
+    ////////////////////////////////////////
+    // Parameters
+
+    for _ , p := range Params {
+        n   := p.Get( v )
+        new := n
+
+        if ( p.Unused ) {
+            new = 0
+        } else if ( n < p.Min ) {
+            new = p.Min
+        } else if ( n > p.Max ) {
+            new = p.Max
+        }
+
+        if ( new != n ) {
+            p.Set( v , new )
+            change( p.Name , fmt.Sprintf( "%d" , n ) , fmt.Sprintf( "%d" , new ) )
+        }
+    }
+
+    ////////////////////////////////////////
+    // Name
+
+    new := volca_name( v.Name )
+    if ( len( new ) > name_len ) {
+        new = new[:name_len]
+    }
+    new += strings.Repeat( " " , name_len - len( new ) )
+
+    if ( new != v.Name ) {
+        change( "NAME" , fmt.Sprintf( "%q" , v.Name ) , fmt.Sprintf( "%q" , new ) )
+        v.Name = new
+    }
+
+    return rv
+}
+
+////////////////////////////////////////
+// Replace the characters in a name which the Volca can't show. Lower case
+// letters become upper case, a few others become something which looks
+// similar, and anything else becomes a space.
+
+var name_replace = map[rune]rune{
+    '\\'    : '/' ,
+    '~'     : '-' ,
+    '`'     : '\'' ,
+    '{'     : '(' ,
+    '}'     : ')' ,
+    '|'     : '!' ,
+}
+
+func volca_name( name string ) string {
+    return strings.Map( func( c rune ) rune {
+        if ( ( c >= 'a' ) && ( c <= 'z' ) ) {
+            return c - 'a' + 'A'
+        } else if r , ok := name_replace[c] ; ok {
+            return r
+        } else if ( !strings.ContainsRune( VolcaChars , c ) ) {
+            return ' '
+        }
+        return c
+    } , name )
+}
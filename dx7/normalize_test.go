@@ -0,0 +1,76 @@
+// volca-convert - dx7/normalize_test.go
+// John Simpson <jms1@jms1.net> 2022-09-29
+
+package dx7
+
+import (
+    "strings"
+    "testing"
+)
+
+func TestNormalize( t *testing.T ) {
+    ////////////////////////////////////////
+    // Valid voices aren't changed, except for names the Volca can't show
+    // (some of the generated names have a "\" in them)
+
+    bank := test_bank( 32 )
+    for _ , c := range NormalizeBank( &bank ) {
+        if ( ( c.Param != "NAME" ) || !strings.Contains( c.Old , "\\" ) ) {
+            t.Errorf( "unexpected change: %v" , c )
+        }
+    }
+
+    if p := Lint( bank ) ; len( p ) > 0 {
+        t.Errorf( "normalized bank still has problems: %v" , p )
+    }
+
+    ////////////////////////////////////////
+    // Invalid values are fixed
+
+    v := InitVoice()
+    v.Name        = "Café au lait"
+    v.ALL.FDBK    = 12
+    v.OP[1].DETU  = 15
+    v.OP[5].XX15  = 3
+
+    want := []string{
+        `voice 7 "Café au lait" OP2.DETU: 15 -> 14` ,
+        `voice 7 "Café au lait" OP6.XX15: 3 -> 0` ,
+        `voice 7 "Café au lait" ALL.FDBK: 12 -> 7` ,
+        `voice 7 "Café au lait" NAME: "Café au lait" -> "CAF  AU LA"` ,
+    }
+
+    got := Normalize( &v , 7 )
+    if ( len( got ) != len( want ) ) {
+        t.Fatalf( "got %d changes, expected %d: %v" , len( got ) , len( want ) , got )
+    }
+
+    for n := range got {
+        if ( got[n].String() != want[n] ) {
+            t.Errorf( "got:\n%s\nexpected:\n%s" , got[n] , want[n] )
+        }
+    }
+
+    if ( len( LintVoice( &v , 7 ) ) > 0 ) {
+        t.Errorf( "normalized voice still has problems: %v" , LintVoice( &v , 7 ) )
+    }
+
+    ////////////////////////////////////////
+    // Short names are padded
+
+    v = InitVoice()
+    v.Name = "SHORT"
+    Normalize( &v , 1 )
+    if ( v.Name != "SHORT     " ) {
+        t.Errorf( "name is %q" , v.Name )
+    }
+
+    ////////////////////////////////////////
+    // Characters the Volca can't show are replaced
+
+    v.Name = "{a|b}~c\\`d"
+    Normalize( &v , 1 )
+    if ( v.Name != "(A!B)-C/'D" ) {
+        t.Errorf( "name is %q" , v.Name )
+    }
+}
@@ -0,0 +1,112 @@
+// volca-convert - dx7/order.go
+// John Simpson <jms1@jms1.net> 2022-10-03
+//
+// Change the order of the voices in a bank.
+//
+// Voice numbers start at 1, the same as the DX7 and Volca show them.
+
+package dx7
+
+import (
+    "fmt"
+    "sort"
+    "strings"
+)
+
+///////////////////////////////////////////////////////////////////////////////
+//
+// Sort the voices by name, or by the value of a parameter (i.e. "ALGO" or
+// "OP1.OLVL"). Names are compared without regard to upper/lower case.
+// Voices with the same name or value stay in the same order they were in.
+// If "key" starts with '-', the order is reversed (largest first).
+
+func SortBank( bank *Bank , key string ) error {
+    desc := strings.HasPrefix( key , "-" )
+    key   = strings.ToUpper( strings.TrimPrefix( key , "-" ) )
+
+    var less func( a *Voice , b *Voice ) bool
+
+    if ( key == "NAME" ) {
+        less = func( a *Voice , b *Voice ) bool {
+            return strings.ToUpper( a.Name ) < strings.ToUpper( b.Name )
+        }
+    } else {
+        p := ParamByName( key )
+        if ( ( p == nil ) || p.Unused ) {
+            return fmt.Errorf( "can't sort by \"%s\", no such parameter" , key )
+        }
+
+        less = func( a *Voice , b *Voice ) bool {
+            return p.Get( a ) < p.Get( b )
+        }
+    }
+
+    sort.SliceStable( bank.Voices , func( i , j int ) bool {
+        if ( desc ) {
+            return less( &bank.Voices[j] , &bank.Voices[i] )
+        }
+        return less( &bank.Voices[i] , &bank.Voices[j] )
+    } )
+
+    return nil
+}
+
+///////////////////////////////////////////////////////////////////////////////
+//
+// Move one voice to a different position. The voices in between shift by
+// one to make room, i.e. moving 12 to 1 makes the old 1-11 into 2-12.
+
+func MoveVoice( bank *Bank , from int , to int ) error {
+    if err := check_voice_num( bank , from ) ; err != nil {
+        return err
+    }
+    if err := check_voice_num( bank , to ) ; err != nil {
+        return err
+    }
+
+    v := bank.Voices[ from - 1 ]
+
+    voices := append( []Voice{} , bank.Voices[:(from-1)]... )
+    voices  = append( voices , bank.Voices[from:]... )
+
+    bank.Voices = append( voices[:(to-1)] , append( []Voice{ v } , voices[(to-1):]... )... )
+
+    return nil
+}
+
+////////////////////////////////////////
+// Swap two voices
+
+func SwapVoices( bank *Bank , a int , b int ) error {
+    if err := check_voice_num( bank , a ) ; err != nil {
+        return err
+    }
+    if err := check_voice_num( bank , b ) ; err != nil {
+        return err
+    }
+
+    bank.Voices[ a - 1 ] , bank.Voices[ b - 1 ] = bank.Voices[ b - 1 ] , bank.Voices[ a - 1 ]
+
+    return nil
+}
+
+////////////////////////////////////////
+// Reverse the order of the voices
+
+func ReverseBank( bank *Bank ) {
+    v := bank.Voices
+    for i , j := 0 , len( v ) - 1 ; i < j ; i , j = i + 1 , j - 1 {
+        v[i] , v[j] = v[j] , v[i]
+    }
+}
+
+////////////////////////////////////////
+
+func check_voice_num( bank *Bank , n int ) error {
+    if ( ( n < 1 ) || ( n > len( bank.Voices ) ) ) {
+        return fmt.Errorf( "voice %d doesn't exist, there are only %d voices" ,
+            n , len( bank.Voices ) )
+    }
+
+    return nil
+}
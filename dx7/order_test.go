@@ -0,0 +1,88 @@
+// volca-convert - dx7/order_test.go
+// John Simpson <jms1@jms1.net> 2022-10-03
+
+package dx7
+
+import (
+    "testing"
+)
+
+////////////////////////////////////////
+// Build a bank whose voices are named by a list of letters, and return
+// the names in a bank as the same kind of string.
+
+func order_bank( names string ) Bank {
+    var bank Bank
+
+    for n , c := range names {
+        v := InitVoice()
+        v.Name = string( c )
+        v.ALGO = byte( n % 3 )
+        bank.Voices = append( bank.Voices , v )
+    }
+
+    return bank
+}
+
+func order_names( bank Bank ) string {
+    rv := ""
+    for _ , v := range bank.Voices {
+        rv += v.Name
+    }
+
+    return rv
+}
+
+func TestReorder( t *testing.T ) {
+    tests := []struct {
+        what    string
+        op      func( b *Bank ) error
+        want    string
+    }{
+        { "move 5:1"    , func( b *Bank ) error { return MoveVoice( b , 5 , 1 ) } , "EABCDF" } ,
+        { "move 1:6"    , func( b *Bank ) error { return MoveVoice( b , 1 , 6 ) } , "BCDEFA" } ,
+        { "move 2:4"    , func( b *Bank ) error { return MoveVoice( b , 2 , 4 ) } , "ACDBEF" } ,
+        { "move 3:3"    , func( b *Bank ) error { return MoveVoice( b , 3 , 3 ) } , "ABCDEF" } ,
+        { "swap 1,6"    , func( b *Bank ) error { return SwapVoices( b , 1 , 6 ) } , "FBCDEA" } ,
+        { "reverse"     , func( b *Bank ) error { ReverseBank( b ) ; return nil } , "FEDCBA" } ,
+        { "sort ALGO"   , func( b *Bank ) error { return SortBank( b , "ALGO" ) } , "ADBECF" } ,
+        { "sort -algo"  , func( b *Bank ) error { return SortBank( b , "-algo" ) } , "CFBEAD" } ,
+    }
+
+    for _ , test := range tests {
+        bank := order_bank( "ABCDEF" )
+        if err := test.op( &bank ) ; err != nil {
+            t.Errorf( "%s: %v" , test.what , err )
+        } else if got := order_names( bank ) ; got != test.want {
+            t.Errorf( "%s: got %s, expected %s" , test.what , got , test.want )
+        }
+    }
+
+    ////////////////////////////////////////
+    // Names are sorted without regard to case
+
+    bank := order_bank( "dAcB" )
+    if err := SortBank( &bank , "NAME" ) ; err != nil {
+        t.Fatal( err )
+    }
+    if got := order_names( bank ) ; got != "ABcd" {
+        t.Errorf( "sort NAME: got %s, expected ABcd" , got )
+    }
+
+    ////////////////////////////////////////
+    // Errors
+
+    bank = order_bank( "ABC" )
+    if err := MoveVoice( &bank , 4 , 1 ) ; err == nil {
+        t.Errorf( "move 4:1: expected an error" )
+    }
+    if err := SwapVoices( &bank , 0 , 1 ) ; err == nil {
+        t.Errorf( "swap 0,1: expected an error" )
+    }
+    if err := SortBank( &bank , "XX08" ) ; err == nil {
+        t.Errorf( "sort XX08: expected an error" )
+    }
+    if got := order_names( bank ) ; got != "ABC" {
+        t.Errorf( "failed operations changed the bank: %s" , got )
+    }
+}
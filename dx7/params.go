@@ -0,0 +1,317 @@
+// volca-convert - dx7/params.go
+// John Simpson <jms1@jms1.net> 2022-09-11
+//
+// Parameter descriptor table.
+//
+// Every parameter in a voice is described exactly once, here. The readers
+// and writers for every file format use this table to find where each
+// parameter lives, so adding or fixing a parameter only involves changing
+// this file.
+
+package dx7
+
+import (
+    "fmt"
+)
+
+///////////////////////////////////////////////////////////////////////////////
+//
+// Descriptor for one parameter
+
+type Param struct {
+    Name    string  // name used in files, i.e. "OP3.EGR1" or "ALGO"
+    Group   string  // "OP1" - "OP6", "ALL", or "" for top-level parameters
+    Field   string  // name within the group, i.e. "EGR1"
+    Display string  // human-readable name, used in messages
+
+    Off155  int     // offset within a 155-byte voice (-1 = not stored)
+    Off128  int     // offset within a 128-byte voice
+    Mask    byte    // bits used within the 128-byte voice (before shifting)
+    Shift   uint    // how far left the bits are shifted
+
+    Min     byte    // valid range
+    Max     byte
+    Default byte    // value in the DX7's "INIT VOICE"
+
+    // Unused bits which are only present in the 32-voice format. These
+    // are not real parameters and are not shown in JSON, CSV, or TEXT.
+    Unused  bool
+
+    ref     func( v *Voice ) *byte
+}
+
+////////////////////////////////////////
+// Get/set this parameter's value within a voice
+
+func (p *Param) Get( v *Voice ) byte {
+    return *p.ref( v )
+}
+
+func (p *Param) Set( v *Voice , n byte ) {
+    *p.ref( v ) = n
+}
+
+////////////////////////////////////////
+// Message for a value outside of this parameter's range, i.e.
+// "Detune must be 0..14"
+
+func (p *Param) range_msg() string {
+    return fmt.Sprintf( "%s must be %d..%d" , p.Display , p.Min , p.Max )
+}
+
+///////////////////////////////////////////////////////////////////////////////
+//
+// Templates used to build the table.
+//
+// Offsets for operator parameters are relative to the start of the
+// operator's block. Operators are stored in reverse order (OP6 first),
+// each one using 21 bytes in the 155-byte format and 17 bytes in the
+// 128-byte format.
+//
+// Offsets for voice-wide parameters are relative to the start of the
+// voice-wide block, which starts at byte 126 in the 155-byte format and
+// byte 102 in the 128-byte format.
+
+type param_def struct {
+    field   string
+    display string
+    off155  int
+    off128  int
+    mask    byte
+    shift   uint
+    min     byte
+    max     byte
+    def     byte
+    unused  bool
+    opref   func( o *Operator ) *byte
+    vref    func( v *Voice ) *byte
+}
+
+const (
+    op155_size  = 21
+    op128_size  = 17
+    all155_loc  = 126
+    all128_loc  = 102
+    name155_loc = 145
+    name128_loc = 118
+    name_len    = 10
+)
+
+////////////////////////////////////////
+// Parameters controlled by dedicated knobs on the Volca. These appear at
+// the top level of JSON files, and at the start of each CSV row.
+
+var top_defs = []param_def{
+//    field    display                      155   128   mask   sh  min max def
+    { "ALGO" , "Algorithm"                 ,  8 ,  8 , 0x1F , 0 , 0 , 31 ,  0 , false , nil , func( v *Voice ) *byte { return &v.ALGO } } ,
+    { "LFOR" , "LFO Speed"                 , 11 , 10 , 0x7F , 0 , 0 , 99 , 35 , false , nil , func( v *Voice ) *byte { return &v.LFOR } } ,
+    { "LPMD" , "LFO Pitch Mod Depth"       , 13 , 12 , 0x7F , 0 , 0 , 99 ,  0 , false , nil , func( v *Voice ) *byte { return &v.LPMD } } ,
+    { "XX08" , "Unused bits (ALGO byte)"   , -1 ,  8 , 0x03 , 5 , 0 ,  3 ,  0 , true  , nil , func( v *Voice ) *byte { return &v.XX08 } } ,
+    { "XX09" , "Unused bits (FDBK byte)"   , -1 ,  9 , 0x07 , 4 , 0 ,  7 ,  0 , true  , nil , func( v *Voice ) *byte { return &v.XX09 } } ,
+}
+
+////////////////////////////////////////
+// Operator parameters, in the order used by the Volca FM/FM2 menus (which
+// is also the order they appear in a one-voice SYX file).
+
+var op_defs = []param_def{
+//    field    display                      155   128   mask   sh  min max def
+    { "EGR1" , "EG Rate 1"                 ,  0 ,  0 , 0x7F , 0 , 0 , 99 , 99 , false , func( o *Operator ) *byte { return &o.EGR1 } , nil } ,
+    { "EGR2" , "EG Rate 2"                 ,  1 ,  1 , 0x7F , 0 , 0 , 99 , 99 , false , func( o *Operator ) *byte { return &o.EGR2 } , nil } ,
+    { "EGR3" , "EG Rate 3"                 ,  2 ,  2 , 0x7F , 0 , 0 , 99 , 99 , false , func( o *Operator ) *byte { return &o.EGR3 } , nil } ,
+    { "EGR4" , "EG Rate 4"                 ,  3 ,  3 , 0x7F , 0 , 0 , 99 , 99 , false , func( o *Operator ) *byte { return &o.EGR4 } , nil } ,
+    { "EGL1" , "EG Level 1"                ,  4 ,  4 , 0x7F , 0 , 0 , 99 , 99 , false , func( o *Operator ) *byte { return &o.EGL1 } , nil } ,
+    { "EGL2" , "EG Level 2"                ,  5 ,  5 , 0x7F , 0 , 0 , 99 , 99 , false , func( o *Operator ) *byte { return &o.EGL2 } , nil } ,
+    { "EGL3" , "EG Level 3"                ,  6 ,  6 , 0x7F , 0 , 0 , 99 , 99 , false , func( o *Operator ) *byte { return &o.EGL3 } , nil } ,
+    { "EGL4" , "EG Level 4"                ,  7 ,  7 , 0x7F , 0 , 0 , 99 ,  0 , false , func( o *Operator ) *byte { return &o.EGL4 } , nil } ,
+    { "LSBP" , "Level Scaling Break Point" ,  8 ,  8 , 0x7F , 0 , 0 , 99 , 39 , false , func( o *Operator ) *byte { return &o.LSBP } , nil } ,
+    { "LSLD" , "Level Scaling Left Depth"  ,  9 ,  9 , 0x7F , 0 , 0 , 99 ,  0 , false , func( o *Operator ) *byte { return &o.LSLD } , nil } ,
+    { "LSRD" , "Level Scaling Right Depth" , 10 , 10 , 0x7F , 0 , 0 , 99 ,  0 , false , func( o *Operator ) *byte { return &o.LSRD } , nil } ,
+    { "LSLC" , "Level Scaling Left Curve"  , 11 , 11 , 0x03 , 0 , 0 ,  3 ,  0 , false , func( o *Operator ) *byte { return &o.LSLC } , nil } ,
+    { "LSRC" , "Level Scaling Right Curve" , 12 , 11 , 0x03 , 2 , 0 ,  3 ,  0 , false , func( o *Operator ) *byte { return &o.LSRC } , nil } ,
+    { "ORS"  , "Oscillator Rate Scaling"   , 13 , 12 , 0x07 , 0 , 0 ,  7 ,  0 , false , func( o *Operator ) *byte { return &o.ORS  } , nil } ,
+    { "AMS"  , "Amp Mod Sensitivity"       , 14 , 13 , 0x03 , 0 , 0 ,  3 ,  0 , false , func( o *Operator ) *byte { return &o.AMS  } , nil } ,
+    { "KVS"  , "Key Velocity Sensitivity"  , 15 , 13 , 0x07 , 2 , 0 ,  7 ,  0 , false , func( o *Operator ) *byte { return &o.KVS  } , nil } ,
+    { "OLVL" , "Output Level"              , 16 , 14 , 0x7F , 0 , 0 , 99 ,  0 , false , func( o *Operator ) *byte { return &o.OLVL } , nil } ,
+    { "OSCM" , "Oscillator Mode"           , 17 , 15 , 0x01 , 0 , 0 ,  1 ,  0 , false , func( o *Operator ) *byte { return &o.OSCM } , nil } ,
+    { "FREC" , "Frequency Coarse"          , 18 , 15 , 0x1F , 1 , 0 , 31 ,  1 , false , func( o *Operator ) *byte { return &o.FREC } , nil } ,
+    { "FREF" , "Frequency Fine"            , 19 , 16 , 0x7F , 0 , 0 , 99 ,  0 , false , func( o *Operator ) *byte { return &o.FREF } , nil } ,
+    { "DETU" , "Detune"                    , 20 , 12 , 0x0F , 3 , 0 , 14 ,  7 , false , func( o *Operator ) *byte { return &o.DETU } , nil } ,
+    { "XX11" , "Unused bits (LSLC byte)"   , -1 , 11 , 0x07 , 4 , 0 ,  7 ,  0 , true  , func( o *Operator ) *byte { return &o.XX11 } , nil } ,
+    { "XX13" , "Unused bits (AMS byte)"    , -1 , 13 , 0x03 , 5 , 0 ,  3 ,  0 , true  , func( o *Operator ) *byte { return &o.XX13 } , nil } ,
+    { "XX15" , "Unused bits (OSCM byte)"   , -1 , 15 , 0x01 , 6 , 0 ,  1 ,  0 , true  , func( o *Operator ) *byte { return &o.XX15 } , nil } ,
+}
+
+////////////////////////////////////////
+// Voice-wide parameters, shown in the "ALL" menu on the Volca.
+
+var all_defs = []param_def{
+//    field    display                      155   128   mask   sh  min max def
+    { "PTR1" , "Pitch EG Rate 1"           ,  0 ,  0 , 0x7F , 0 , 0 , 99 , 99 , false , nil , func( v *Voice ) *byte { return &v.ALL.PTR1 } } ,
+    { "PTR2" , "Pitch EG Rate 2"           ,  1 ,  1 , 0x7F , 0 , 0 , 99 , 99 , false , nil , func( v *Voice ) *byte { return &v.ALL.PTR2 } } ,
+    { "PTR3" , "Pitch EG Rate 3"           ,  2 ,  2 , 0x7F , 0 , 0 , 99 , 99 , false , nil , func( v *Voice ) *byte { return &v.ALL.PTR3 } } ,
+    { "PTR4" , "Pitch EG Rate 4"           ,  3 ,  3 , 0x7F , 0 , 0 , 99 , 99 , false , nil , func( v *Voice ) *byte { return &v.ALL.PTR4 } } ,
+    { "PTL1" , "Pitch EG Level 1"          ,  4 ,  4 , 0x7F , 0 , 0 , 99 , 50 , false , nil , func( v *Voice ) *byte { return &v.ALL.PTL1 } } ,
+    { "PTL2" , "Pitch EG Level 2"          ,  5 ,  5 , 0x7F , 0 , 0 , 99 , 50 , false , nil , func( v *Voice ) *byte { return &v.ALL.PTL2 } } ,
+    { "PTL3" , "Pitch EG Level 3"          ,  6 ,  6 , 0x7F , 0 , 0 , 99 , 50 , false , nil , func( v *Voice ) *byte { return &v.ALL.PTL3 } } ,
+    { "PTL4" , "Pitch EG Level 4"          ,  7 ,  7 , 0x7F , 0 , 0 , 99 , 50 , false , nil , func( v *Voice ) *byte { return &v.ALL.PTL4 } } ,
+    { "FDBK" , "Feedback"                  ,  9 ,  9 , 0x07 , 0 , 0 ,  7 ,  0 , false , nil , func( v *Voice ) *byte { return &v.ALL.FDBK } } ,
+    { "OKS"  , "Oscillator Key Sync"       , 10 ,  9 , 0x01 , 3 , 0 ,  1 ,  1 , false , nil , func( v *Voice ) *byte { return &v.ALL.OKS  } } ,
+    { "LFOD" , "LFO Delay"                 , 12 , 11 , 0x7F , 0 , 0 , 99 ,  0 , false , nil , func( v *Voice ) *byte { return &v.ALL.LFOD } } ,
+    { "LAMD" , "LFO Amp Mod Depth"         , 14 , 13 , 0x7F , 0 , 0 , 99 ,  0 , false , nil , func( v *Voice ) *byte { return &v.ALL.LAMD } } ,
+    { "LFOK" , "LFO Key Sync"              , 15 , 14 , 0x01 , 0 , 0 ,  1 ,  1 , false , nil , func( v *Voice ) *byte { return &v.ALL.LFOK } } ,
+    { "LFOW" , "LFO Wave"                  , 16 , 14 , 0x07 , 1 , 0 ,  5 ,  0 , false , nil , func( v *Voice ) *byte { return &v.ALL.LFOW } } ,
+    { "MSP"  , "Pitch Mod Sensitivity"     , 17 , 14 , 0x07 , 4 , 0 ,  7 ,  3 , false , nil , func( v *Voice ) *byte { return &v.ALL.MSP  } } ,
+    { "TRSP" , "Transpose"                 , 18 , 15 , 0x7F , 0 , 0 , 48 , 24 , false , nil , func( v *Voice ) *byte { return &v.ALL.TRSP } } ,
+}
+
+///////////////////////////////////////////////////////////////////////////////
+//
+// The table itself. Built from the templates above when the package is
+// loaded.
+//
+// Params lists every parameter in the order used by JSON and CSV files:
+// top-level parameters, then OP1 - OP6, then ALL.
+
+var Params []*Param
+
+var by_name = make( map[string]*Param )
+
+////////////////////////////////////////
+// Groups, in the order they appear in files
+
+var Groups = []string{ "" , "OP1" , "OP2" , "OP3" , "OP4" , "OP5" , "OP6" , "ALL" }
+
+func init() {
+    ////////////////////////////////////////
+    // Top-level parameters. These are stored in the voice-wide block
+    // of the SYX formats.
+
+    for _ , d := range top_defs {
+        add_param( d , "" , d.field , all155_loc , all128_loc , d.vref )
+    }
+
+    ////////////////////////////////////////
+    // Operator parameters
+
+    for opn := 0 ; opn < 6 ; opn ++ {
+        group  := fmt.Sprintf( "OP%d" , opn + 1 )
+        loc155 := op155_size * ( 5 - opn )
+        loc128 := op128_size * ( 5 - opn )
+
+        for _ , d := range op_defs {
+            n     := opn
+            opref := d.opref
+            ref   := func( v *Voice ) *byte { return opref( &v.OP[n] ) }
+
+            add_param( d , group , group + "." + d.field , loc155 , loc128 , ref )
+        }
+    }
+
+    ////////////////////////////////////////
+    // Voice-wide parameters
+
+    for _ , d := range all_defs {
+        add_param( d , "ALL" , "ALL." + d.field , all155_loc , all128_loc , d.vref )
+    }
+}
+
+func add_param( d param_def , group string , name string ,
+    loc155 int , loc128 int , ref func( v *Voice ) *byte ) {
+
+    p := &Param{
+        Name    : name ,
+        Group   : group ,
+        Field   : d.field ,
+        Display : d.display ,
+        Off155  : -1 ,
+        Off128  : loc128 + d.off128 ,
+        Mask    : d.mask ,
+        Shift   : d.shift ,
+        Min     : d.min ,
+        Max     : d.max ,
+        Default : d.def ,
+        Unused  : d.unused ,
+        ref     : ref ,
+    }
+
+    if ( d.off155 >= 0 ) {
+        p.Off155 = loc155 + d.off155
+    }
+
+    Params = append( Params , p )
+    by_name[name] = p
+}
+
+///////////////////////////////////////////////////////////////////////////////
+//
+// Find a parameter by name, i.e. "OP3.EGR1". Returns nil if there is no
+// parameter with that name.
+
+func ParamByName( name string ) *Param {
+    return by_name[name]
+}
+
+///////////////////////////////////////////////////////////////////////////////
+//
+// Return the parameters in one group, in file order. Unused bits are not
+// included.
+
+func GroupParams( group string ) []*Param {
+    var rv []*Param
+
+    for _ , p := range Params {
+        if ( ( p.Group == group ) && !p.Unused ) {
+            rv = append( rv , p )
+        }
+    }
+
+    return rv
+}
+
+///////////////////////////////////////////////////////////////////////////////
+//
+// Return every parameter which appears in JSON and CSV files, in file order.
+
+func file_params() []*Param {
+    var rv []*Param
+
+    for _ , p := range Params {
+        if ( !p.Unused ) {
+            rv = append( rv , p )
+        }
+    }
+
+    return rv
+}
+
+///////////////////////////////////////////////////////////////////////////////
+//
+// Return the unused bits from the 32-voice SYX format, in file order.
+// These are only written to JSON and CSV files when they aren't all zero,
+// see has_raw_bits().
+
+func raw_params() []*Param {
+    var rv []*Param
+
+    for _ , p := range Params {
+        if ( p.Unused ) {
+            rv = append( rv , p )
+        }
+    }
+
+    return rv
+}
+
+////////////////////////////////////////
+// Return true if any of a voice's unused bits are set
+
+func has_raw_bits( v *Voice ) bool {
+    for _ , p := range raw_params() {
+        if ( p.Get( v ) != 0 ) {
+            return true
+        }
+    }
+
+    return false
+}
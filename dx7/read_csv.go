@@ -0,0 +1,316 @@
+// volca-convert - dx7/read_csv.go
+// John Simpson <jms1@jms1.net> 2022-08-27
+//
+// Read a CSV file into memory.
+
+package dx7
+
+import (
+    "bytes"
+    "fmt"
+    "io"
+    "strconv"
+    "encoding/csv"
+)
+
+///////////////////////////////////////////////////////////////////////////////
+//
+// CSV codec. Encode() is in write_csv.go.
+
+type csv_codec struct{}
+
+var CSVCodec Codec = csv_codec{}
+
+func init() {
+    Register( CSVCodec )
+}
+
+func (csv_codec) Names() []string       { return []string{ "CSV" } }
+func (csv_codec) Description() string   { return "one row per voice, for spreadsheets" }
+func (csv_codec) Extensions() []string  { return []string{ ".csv" } }
+func (csv_codec) CanDecode() bool       { return true }
+func (csv_codec) CanEncode() bool       { return true }
+func (csv_codec) Binary() bool          { return false }
+
+////////////////////////////////////////
+// CSV files start with two header rows. Somewhere in the first row there
+// will be an "OP1" cell, and the cell below it will be "EGR1".
+
+func (csv_codec) Sniff( head []byte ) bool {
+    csvr := csv.NewReader( bytes.NewReader( head ) )
+    csvr.FieldsPerRecord = -1
+
+    h1 , err1 := csvr.Read()
+    h2 , err2 := csvr.Read()
+    if ( ( err1 != nil ) || ( err2 != nil ) ) {
+        return false
+    }
+
+    for c := 0 ; ( c < len( h1 ) ) && ( c < len( h2 ) ) ; c ++ {
+        if ( ( h1[c] == "OP1" ) && ( h2[c] == "EGR1" ) ) {
+            return true
+        }
+    }
+
+    return false
+}
+
+///////////////////////////////////////////////////////////////////////////////
+//
+// Read a CSV file into memory.
+//
+// The file MUST start with the header rows generated by write_csv.go, as
+// these are used to figure out which parameter is in each column. Columns
+// for the unused bits (i.e. "OP1" / "XX11") are optional.
+//
+// Problems with the header rows, including unknown or duplicate columns,
+// are returned as a ParseError. Missing columns and cells which aren't
+// valid values are returned as *ValueError (or an ErrorList of them),
+// along with the voices, the same as for JSON files.
+
+func ReadCSV( filename string ) ( Bank , error ) {
+    return ReadFile( filename , CSVCodec )
+}
+
+func (csv_codec) Decode( r io.Reader ) ( Bank , error ) {
+    var bank Bank
+    var cell [][]string
+    var errs ErrorList
+
+    ////////////////////////////////////////
+    // Read the file's contents
+
+    csvr := csv.NewReader( r )
+
+    cell , err := csvr.ReadAll()
+    if err != nil {
+        if ce , ok := err.( *csv.ParseError ) ; ok {
+            return bank , &ParseError{
+                Offset  : -1 ,
+                Row     : ce.Line ,
+                Column  : ce.Column ,
+                Msg     : ce.Err.Error() ,
+            }
+        }
+        return bank , err
+    }
+
+    ////////////////////////////////////////
+    // Make sure the file has the correct headers
+
+    if ( ( len( cell ) < 2 ) || ( len( cell[0] ) < 5 ) || ( len( cell[1] ) < 5 ) ) {
+        return bank , &ParseError{
+            Offset  : -1 ,
+            Row     : 1 ,
+            Msg     : "missing CSV header rows" ,
+        }
+    }
+
+    if ( cell[0][4] != "OP1" ) {
+        return bank , &ParseError{
+            Offset  : -1 ,
+            Row     : 1 ,
+            Column  : 5 ,
+            Msg     : "invalid CSV header" ,
+        }
+    }
+
+    if ( cell[1][4] != "EGR1" ) {
+        return bank , &ParseError{
+            Offset  : -1 ,
+            Row     : 2 ,
+            Column  : 5 ,
+            Msg     : "invalid CSV header" ,
+        }
+    }
+
+    ////////////////////////////////////////
+    // Figure out which parameter is in each column. The header rows
+    // contain the group and field names.
+
+    params := make( []*Param , len( cell[1] ) )
+
+    for c := 1 ; c < len( cell[1] ) ; c ++ {
+        var k string
+
+        if ( cell[0][c] == "" ) {
+            k = cell[1][c]
+        } else {
+            k = cell[0][c] + "." + cell[1][c]
+        }
+
+        ////////////////////////////////////////
+        // The writer can add each operator's role, which is only for
+        // humans. Leave params[c] as nil so the column is skipped.
+
+        if ( cell[1][c] == "ROLE" ) {
+            continue
+        }
+
+        params[c] = ParamByName( k )
+        if ( params[c] == nil ) {
+            return bank , &ParseError{
+                Offset  : -1 ,
+                Row     : 2 ,
+                Column  : c + 1 ,
+                Msg     : fmt.Sprintf( "unknown parameter \"%s\"" , k ) ,
+            }
+        }
+
+        for x := 1 ; x < c ; x ++ {
+            if ( params[x] == params[c] ) {
+                return bank , &ParseError{
+                    Offset  : -1 ,
+                    Row     : 2 ,
+                    Column  : c + 1 ,
+                    Msg     : fmt.Sprintf( "duplicate parameter \"%s\" (also in col %d)" ,
+                        k , x + 1 ) ,
+                }
+            }
+        }
+    }
+
+    ////////////////////////////////////////
+    // Every parameter needs a column, except for the unused bits
+
+    var missing []*Param
+    for _ , p := range file_params() {
+        found := false
+        for _ , x := range params {
+            if ( x == p ) {
+                found = true
+            }
+        }
+
+        if ( !found ) {
+            missing = append( missing , p )
+        }
+    }
+
+    ////////////////////////////////////////
+    // The file is symbolic if any cell other than DETU uses a symbolic
+    // value (see below). A signed DETU means the same in either kind of
+    // file, so it doesn't decide anything.
+
+    symbolic := ""
+    for r := 2 ; ( r < len( cell ) ) && ( symbolic == "" ) ; r ++ {
+        for c := 1 ; ( c < len( cell[r] ) ) && ( c < len( params ) ) ; c ++ {
+            p := params[c]
+            if ( ( p != nil ) && p.Symbolic() && ( p.Field != "DETU" ) && csv_symbol( cell[r][c] ) ) {
+                symbolic = fmt.Sprintf( "%s=%s in row %d" , p.Name , cell[r][c] , r + 1 )
+                break
+            }
+        }
+    }
+
+    ////////////////////////////////////////
+    // Process rows
+
+    for r := 2 ; r < len( cell ) ; r ++ {
+        var v Voice
+
+        problem := func( p *Param , text string , msg string ) {
+            errs = append( errs , &ValueError{
+                Voice   : r - 1 ,
+                Name    : v.Name ,
+                Param   : p.Name ,
+                Value   : text ,
+                Msg     : msg ,
+            } )
+        }
+
+        ////////////////////////////////////////
+        // Start with the default values, so that missing or unreadable
+        // values have something sensible.
+
+        for _ , p := range file_params() {
+            p.Set( &v , p.Default )
+        }
+
+        ////////////////////////////////////////
+        // Store the name
+
+        v.Name = clean_name( cell[r][0] )
+
+        for _ , p := range missing {
+            problem( p , "" , "missing" )
+        }
+
+        ////////////////////////////////////////
+        // Store the other fields
+
+        for c := 1 ; c < len( cell[r] ) ; c ++ {
+            if ( params[c] == nil ) {
+                continue
+            }
+
+            p := params[c]
+
+            ////////////////////////////////////////
+            // Symbolic values (i.e. "SINE" or "+3") start with something
+            // other than a digit. In a symbolic file, DETU has to have a
+            // sign, since "3" could mean either +3 or the number 3 (-4).
+            //
+            // Values which can't be read are set to the parameter's
+            // default, and reported along with the voices.
+
+            text := cell[r][c]
+            if ( ( symbolic != "" ) && ( p.Field == "DETU" ) && !csv_symbol( text ) ) {
+                problem( p , text , "must be -7..+7 with a sign (i.e. \"+3\"), " +
+                    "since this file uses symbolic values (" + symbolic + ")" )
+                p.Set( &v , p.Default )
+                continue
+            }
+
+            if ( p.Symbolic() && csv_symbol( text ) ) {
+                n , err := p.ParseSymbol( text )
+                if ( err != nil ) {
+                    problem( p , text , err.Error() )
+                    n = p.Default
+                }
+
+                p.Set( &v , n )
+                continue
+            }
+
+            ////////////////////////////////////////
+            // Get the numeric value from the cell. Values outside of the
+            // parameter's range are set to the closest valid value.
+
+            n , err := strconv.Atoi( text )
+            if err != nil {
+                problem( p , text , "must be a whole number" )
+                p.Set( &v , p.Default )
+                continue
+            }
+
+            if ( n < int( p.Min ) ) {
+                problem( p , text , p.range_msg() )
+                n = int( p.Min )
+            } else if ( n > int( p.Max ) ) {
+                problem( p , text , p.range_msg() )
+                n = int( p.Max )
+            }
+
+            p.Set( &v , byte( n ) )
+        }
+
+        bank.Voices = append( bank.Voices , v )
+    }
+
+    if ( len( errs ) == 1 ) {
+        return bank , errs[0]
+    } else if ( len( errs ) > 1 ) {
+        return bank , errs
+    }
+
+    return bank , nil
+}
+
+////////////////////////////////////////
+// Return true if a cell holds a symbolic value, which starts with
+// something other than a digit
+
+func csv_symbol( text string ) bool {
+    return ( text != "" ) && ( ( text[0] < '0' ) || ( text[0] > '9' ) )
+}
@@ -0,0 +1,120 @@
+// volca-convert - dx7/read_csv_test.go
+// John Simpson <jms1@jms1.net> 2022-09-28
+//
+// Make sure problems in CSV files are reported the way we expect.
+
+package dx7
+
+import (
+    "bytes"
+    "strings"
+    "testing"
+
+    "encoding/csv"
+)
+
+////////////////////////////////////////
+// Generate a CSV file containing one voice, with some cells changed. The
+// keys are parameter names, i.e. "OP1.DETU".
+
+func csv_with( opt Options , change map[string]string ) string {
+    bank := Bank{ Voices: []Voice{ InitVoice() } }
+
+    cell , _ := csv.NewReader( strings.NewReader( csv_generate( bank , opt ) ) ).ReadAll()
+    for c := range cell[1] {
+        k := cell[1][c]
+        if ( cell[0][c] != "" ) {
+            k = cell[0][c] + "." + k
+        }
+
+        if text , ok := change[k] ; ok {
+            cell[2][c] = text
+        }
+    }
+
+    var buf bytes.Buffer
+    csv.NewWriter( &buf ).WriteAll( cell )
+    return buf.String()
+}
+
+func TestCSVProblems( t *testing.T ) {
+    text := csv_with( Options{} , map[string]string{
+        "ALGO"      : "x" ,
+        "OP1.EGR1"  : "300" ,
+        "OP2.DETU"  : "15" ,
+        "ALL.FDBK"  : "-1" ,
+    } )
+
+    ////////////////////////////////////////
+    // Values which can't be stored are reported by the reader, and
+    // replaced with something valid
+
+    want := "voice 1 \"INIT VOICE\" ALGO=x: must be a whole number\n" +
+        "voice 1 \"INIT VOICE\" OP1.EGR1=300: EG Rate 1 must be 0..99\n" +
+        "voice 1 \"INIT VOICE\" OP2.DETU=15: Detune must be 0..14\n" +
+        "voice 1 \"INIT VOICE\" ALL.FDBK=-1: Feedback must be 0..7"
+
+    bank , err := CSVCodec.Decode( strings.NewReader( text ) )
+    if ( !Recoverable( err ) ) {
+        t.Fatalf( "expected a recoverable error, got %v" , err )
+    } else if ( err.Error() != want ) {
+        t.Errorf( "got:\n%s\nexpected:\n%s" , err , want )
+    }
+
+    v := bank.Voices[0]
+    if ( ( v.ALGO != 0 ) || ( v.OP[0].EGR1 != 99 ) || ( v.OP[1].DETU != 14 ) || ( v.ALL.FDBK != 0 ) ) {
+        t.Errorf( "ALGO=%d EGR1=%d DETU=%d FDBK=%d, expected 0, 99, 14, and 0" ,
+            v.ALGO , v.OP[0].EGR1 , v.OP[1].DETU , v.ALL.FDBK )
+    }
+
+    if problems := Lint( bank ) ; len( problems ) > 0 {
+        t.Errorf( "Lint() found %v, expected nothing" , problems )
+    }
+}
+
+func TestCSVColumns( t *testing.T ) {
+    cell , _ := csv.NewReader( strings.NewReader( csv_with( Options{} , nil ) ) ).ReadAll()
+
+    ////////////////////////////////////////
+    // Change the header of one column, i.e. "OP2" / "DETU"
+
+    rename := func( from string , to string ) string {
+        var buf bytes.Buffer
+        w := csv.NewWriter( &buf )
+
+        for r , row := range cell {
+            row = append( []string{} , row... )
+            for c := range row {
+                if ( ( r < 2 ) && ( cell[0][c] + "." + cell[1][c] == from ) ) {
+                    row[c] = strings.Split( to , "." )[r]
+                }
+            }
+            w.Write( row )
+        }
+
+        w.Flush()
+        return buf.String()
+    }
+
+    ////////////////////////////////////////
+    // A missing column is reported for every voice, and the parameter
+    // gets its INIT VOICE value
+
+    bank , err := CSVCodec.Decode( strings.NewReader( rename( "OP2.DETU" , "OP2.ROLE" ) ) )
+    want := "voice 1 \"INIT VOICE\" OP2.DETU: missing"
+    if ( !Recoverable( err ) || ( err.Error() != want ) ) {
+        t.Errorf( "missing column: got %v, expected %s" , err , want )
+    } else if ( bank.Voices[0].OP[1].DETU != 7 ) {
+        t.Errorf( "missing column: DETU=%d, expected 7" , bank.Voices[0].OP[1].DETU )
+    }
+
+    ////////////////////////////////////////
+    // Two columns for the same parameter can't be read
+
+    _ , err = CSVCodec.Decode( strings.NewReader( rename( "OP2.DETU" , "OP2.FREF" ) ) )
+    if _ , ok := err.( *ParseError ) ; !ok {
+        t.Errorf( "duplicate column: got %v, expected a ParseError" , err )
+    } else if ( !strings.Contains( err.Error() , "duplicate parameter \"OP2.FREF\"" ) ) {
+        t.Errorf( "duplicate column: got %v" , err )
+    }
+}
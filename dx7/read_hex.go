@@ -0,0 +1,122 @@
+// volca-convert - dx7/read_hex.go
+// John Simpson <jms1@jms1.net> 2022-09-18
+//
+// Read a SYX file which has been saved as a "hex dump", i.e.
+//
+//     F0 43 00 09 20 00 63 63 ...
+//
+// Some web sites and MIDI tools share patches this way.
+
+package dx7
+
+import (
+    "bytes"
+    "fmt"
+    "io"
+    "io/ioutil"
+    "strconv"
+    "strings"
+)
+
+///////////////////////////////////////////////////////////////////////////////
+//
+// HEX codec. This can only be read, use SYX to write the same data.
+
+type hex_codec struct{}
+
+var HexCodec Codec = hex_codec{}
+
+func init() {
+    Register( HexCodec )
+}
+
+func (hex_codec) Names() []string       { return []string{ "HEX" } }
+func (hex_codec) Description() string   { return "SYX data as a text hex dump" }
+func (hex_codec) Extensions() []string  { return []string{ ".hex" } }
+func (hex_codec) CanDecode() bool       { return true }
+func (hex_codec) CanEncode() bool       { return false }
+func (hex_codec) Binary() bool          { return false }
+
+////////////////////////////////////////
+// The first few "words" are hex bytes, starting with a Yamaha SYSEX header
+
+func (hex_codec) Sniff( head []byte ) bool {
+    words := strings.Fields( string( head ) )
+    if ( len( words ) < 6 ) {
+        return false
+    }
+
+    data , err := hex2bytes( words[:6] )
+    if ( err != nil ) {
+        return false
+    }
+
+    return SYXCodec.Sniff( data )
+}
+
+///////////////////////////////////////////////////////////////////////////////
+//
+// Convert a list of hex "words" to bytes. Each word may have a "0x" prefix
+// and/or a trailing comma.
+
+func hex2bytes( words []string ) ( []byte , error ) {
+    var rv []byte
+
+    for _ , w := range words {
+        w = strings.TrimSuffix( w , "," )
+        w = strings.TrimPrefix( strings.TrimPrefix( w , "0x" ) , "0X" )
+
+        if ( len( w ) != 2 ) {
+            return rv , fmt.Errorf( "\"%s\" is not a hex byte" , w )
+        }
+
+        n , err := strconv.ParseUint( w , 16 , 8 )
+        if ( err != nil ) {
+            return rv , fmt.Errorf( "\"%s\" is not a hex byte" , w )
+        }
+
+        rv = append( rv , byte( n ) )
+    }
+
+    return rv , nil
+}
+
+///////////////////////////////////////////////////////////////////////////////
+//
+// Convert the text to bytes, then read them as a SYX file. Words which
+// aren't hex bytes are reported with their line and column in the text.
+
+func (hex_codec) Decode( r io.Reader ) ( Bank , error ) {
+    var data []byte
+
+    text , err := ioutil.ReadAll( r )
+    if ( err != nil ) {
+        return Bank{} , err
+    }
+
+    for n , line := range strings.Split( string( text ) , "\n" ) {
+        col := 0
+        for _ , w := range strings.Fields( line ) {
+            col += strings.Index( line[col:] , w )
+
+            b , err := hex2bytes( []string{ w } )
+            if ( err != nil ) {
+                return Bank{} , &ParseError{
+                    Offset  : -1 ,
+                    Line    : n + 1 ,
+                    Column  : col + 1 ,
+                    Msg     : err.Error() ,
+                }
+            }
+
+            data = append( data , b... )
+            col += len( w )
+        }
+    }
+
+    return SYXCodec.Decode( bytes.NewReader( data ) )
+}
+
+func (hex_codec) Encode( io.Writer , Bank , Options ) error {
+    return ErrUnsupported
+}
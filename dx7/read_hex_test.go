@@ -0,0 +1,25 @@
+// volca-convert - dx7/read_hex_test.go
+// John Simpson <jms1@jms1.net> 2022-09-29
+//
+// Make sure problems in HEX files point to the right place in the text.
+
+package dx7
+
+import (
+    "strings"
+    "testing"
+)
+
+func TestHexProblems( t *testing.T ) {
+    text := "F0 43 00 00 01 1B\n" +
+        "  63 63 XY 63\n"
+
+    _ , err := HexCodec.Decode( strings.NewReader( text ) )
+
+    want := "line 2 col 9: \"XY\" is not a hex byte"
+    if ( err == nil ) {
+        t.Errorf( "no error, expected %s" , want )
+    } else if ( err.Error() != want ) {
+        t.Errorf( "got %q, expected %q" , err , want )
+    }
+}
@@ -0,0 +1,394 @@
+// volca-convert - dx7/read_json.go
+// John Simpson <jms1@jms1.net> 2022-09-04
+//
+// Read a JSON file into memory.
+
+package dx7
+
+import (
+    "bytes"
+    "fmt"
+    "io"
+    "io/ioutil"
+    "reflect"
+    "sort"
+
+    "encoding/json"
+)
+
+///////////////////////////////////////////////////////////////////////////////
+//
+// JSON codec. Encode() is in write_json.go.
+
+type json_codec struct{}
+
+var JSONCodec Codec = json_codec{}
+
+func init() {
+    Register( JSONCodec )
+}
+
+func (json_codec) Names() []string       { return []string{ "JSON" } }
+func (json_codec) Description() string   { return "list of voices, for editing by hand" }
+func (json_codec) Extensions() []string  { return []string{ ".json" } }
+func (json_codec) CanDecode() bool       { return true }
+func (json_codec) CanEncode() bool       { return true }
+func (json_codec) Binary() bool          { return false }
+
+////////////////////////////////////////
+// JSON files we write start with a list. Files containing a single voice
+// object (without the list around it) are also accepted.
+
+func (json_codec) Sniff( head []byte ) bool {
+    head = bytes.TrimLeft( head , " \t\r\n" )
+    return ( len( head ) > 0 ) && ( ( head[0] == '[' ) || ( head[0] == '{' ) )
+}
+
+////////////////////////////////////////
+// The file is a list of voices (or a single voice). Each voice is an
+// object containing the name and top-level parameters, plus one object for
+// each group ("OP1" through "OP6", and "ALL"). The parameter names come
+// from params.go.
+
+type jvoice map[string]json.RawMessage
+
+///////////////////////////////////////////////////////////////////////////////
+//
+// Read a JSON file into memory.
+//
+// The file is checked strictly. Syntax errors are returned as a ParseError
+// with the line and column. Missing parameters, unknown keys, and values
+// outside of a parameter's range are returned as *ValueError (or an
+// ErrorList of them), along with the voices. See ValueError for what
+// happens to the problem values.
+
+func ReadJSON( filename string ) ( Bank , error ) {
+    return ReadFile( filename , JSONCodec )
+}
+
+func (json_codec) Decode( r io.Reader ) ( Bank , error ) {
+    var bank Bank
+    var errs ErrorList
+
+    ////////////////////////////////////////
+    // Read the file's contents
+
+    jbytes , err := ioutil.ReadAll( r )
+    if err != nil {
+        return bank , err
+    }
+
+    ////////////////////////////////////////
+    // Parse the JSON
+
+    var jvoices []jvoice
+
+    if ( bytes.HasPrefix( bytes.TrimLeft( jbytes , " \t\r\n" ) , []byte( "{" ) ) ) {
+        var jv jvoice
+        err = json.Unmarshal( jbytes , &jv )
+        jvoices = append( jvoices , jv )
+    } else {
+        err = json.Unmarshal( jbytes , &jvoices )
+    }
+
+    if ( err != nil ) {
+        return bank , json_error( err , jbytes )
+    }
+
+    ////////////////////////////////////////
+    // Process voices from JSON
+
+    symbolic := json_symbolic( jvoices )
+
+    for n , jv := range jvoices {
+        v , problems := json_voice( jv , n + 1 , symbolic )
+        bank.Voices = append( bank.Voices , v )
+        errs        = append( errs , problems... )
+    }
+
+    if ( len( errs ) == 1 ) {
+        return bank , errs[0]
+    } else if ( len( errs ) > 1 ) {
+        return bank , errs
+    }
+
+    return bank , nil
+}
+
+///////////////////////////////////////////////////////////////////////////////
+//
+// Build one voice from its JSON object. "num" is the voice number, for
+// error messages, and "symbolic" is true if the file uses symbolic values.
+
+func json_voice( jv jvoice , num int , symbolic bool ) ( Voice , ErrorList ) {
+    var v       Voice
+    var errs    ErrorList
+
+    problem := func( param string , value json.RawMessage , msg string ) {
+        errs = append( errs , &ValueError{
+            Voice   : num ,
+            Name    : v.Name ,
+            Param   : param ,
+            Value   : string( value ) ,
+            Msg     : msg ,
+        } )
+    }
+
+    ////////////////////////////////////////
+    // Start with the default values, so that missing or unreadable values
+    // have something sensible.
+
+    for _ , p := range file_params() {
+        p.Set( &v , p.Default )
+    }
+
+    ////////////////////////////////////////
+    // Copy the name
+
+    if raw , ok := jv["NAME"] ; !ok {
+        problem( "NAME" , nil , "missing" )
+    } else if err := json.Unmarshal( raw , &v.Name ) ; err != nil {
+        problem( "NAME" , raw , "must be a string" )
+    } else {
+        v.Name = clean_name( v.Name )
+    }
+
+    ////////////////////////////////////////
+    // Copy the parameters from each group. Top-level parameters are in
+    // the voice object itself.
+
+    for _ , g := range Groups {
+        obj := map[string]json.RawMessage( jv )
+
+        if ( g != "" ) {
+            raw , ok := jv[g]
+            if ( !ok ) {
+                problem( g , nil , "missing" )
+                continue
+            }
+
+            obj = nil
+            err := json.Unmarshal( raw , &obj )
+            if ( ( err != nil ) || ( obj == nil ) ) {
+                problem( g , nil , "must be an object" )
+                continue
+            }
+        }
+
+        for _ , p := range GroupParams( g ) {
+            raw , ok := obj[ p.Field ]
+            if ( !ok ) {
+                problem( p.Name , nil , "missing" )
+                continue
+            }
+
+            json_value( &v , p , raw , symbolic , problem )
+        }
+
+        ////////////////////////////////////////
+        // Anything else in a group object is unknown, except for "ROLE",
+        // which the writer adds for humans. (Unknown keys at the top level
+        // are checked below.)
+
+        if ( g != "" ) {
+            for _ , k := range sorted_keys( obj ) {
+                if ( k == "ROLE" ) {
+                    continue
+                }
+
+                p := ParamByName( g + "." + k )
+                if ( ( p == nil ) || p.Unused ) {
+                    problem( g + "." + k , nil , "unknown parameter" )
+                }
+            }
+        }
+    }
+
+    ////////////////////////////////////////
+    // Copy the unused bits, if the file has them
+
+    if raw , ok := jv["RAW"] ; ok {
+        var obj map[string]json.RawMessage
+
+        err := json.Unmarshal( raw , &obj )
+        if ( ( err != nil ) || ( obj == nil ) ) {
+            problem( "RAW" , nil , "must be an object" )
+        }
+
+        for _ , k := range sorted_keys( obj ) {
+            p := ParamByName( k )
+            if ( ( p == nil ) || !p.Unused ) {
+                problem( "RAW." + k , nil , "unknown parameter" )
+                continue
+            }
+
+            json_value( &v , p , obj[k] , symbolic , problem )
+        }
+    }
+
+    ////////////////////////////////////////
+    // Check for unknown keys at the top level
+
+    for _ , k := range sorted_keys( jv ) {
+        if ( ( k == "NAME" ) || ( k == "RAW" ) ) {
+            continue
+        }
+
+        p := ParamByName( k )
+        if ( ( p != nil ) && ( p.Group == "" ) && !p.Unused ) {
+            continue
+        }
+
+        known := false
+        for _ , g := range Groups[1:] {
+            if ( k == g ) {
+                known = true
+            }
+        }
+
+        if ( !known ) {
+            problem( k , nil , "unknown parameter" )
+        }
+    }
+
+    return v , errs
+}
+
+////////////////////////////////////////
+// Return true if any parameter other than DETU uses its symbolic form (see
+// Param.Symbol()), which is always a string.
+
+func json_symbolic( jvoices []jvoice ) bool {
+    for _ , jv := range jvoices {
+        for _ , g := range Groups {
+            obj := map[string]json.RawMessage( jv )
+
+            if ( g != "" ) {
+                obj = nil
+                if ( json.Unmarshal( jv[g] , &obj ) != nil ) {
+                    continue
+                }
+            }
+
+            for _ , p := range GroupParams( g ) {
+                raw := bytes.TrimSpace( obj[ p.Field ] )
+                if ( p.Symbolic() && ( p.Field != "DETU" ) && ( len( raw ) > 0 ) && ( raw[0] == '"' ) ) {
+                    return true
+                }
+            }
+        }
+    }
+
+    return false
+}
+
+////////////////////////////////////////
+// Store one value, making sure it's a number within the parameter's range.
+// Parameters with a symbolic form (see Param.Symbol()) also accept that,
+// as a string. In a symbolic file, a DETU number is the detune (-7..+7)
+// rather than the value stored in the file.
+
+func json_value( v *Voice , p *Param , raw json.RawMessage , symbolic bool ,
+    problem func( string , json.RawMessage , string ) ) {
+
+    var n int
+    var s string
+
+    if ( p.Symbolic() ) {
+        if ( ( json.Unmarshal( raw , &s ) != nil ) && symbolic && ( p.Field == "DETU" ) ) {
+            if ( json.Unmarshal( raw , &n ) != nil ) {
+                problem( p.Name , raw , "must be a whole number" )
+                return
+            }
+
+            if ( n < -7 ) {
+                problem( p.Name , raw , "must be -7..+7, since this file uses symbolic values" )
+                n = -7
+            } else if ( n > 7 ) {
+                problem( p.Name , raw , "must be -7..+7, since this file uses symbolic values" )
+                n = 7
+            }
+
+            p.Set( v , byte( n + 7 ) )
+            return
+        }
+
+        if ( s != "" ) {
+            b , err := p.ParseSymbol( s )
+            if ( err != nil ) {
+                problem( p.Name , raw , err.Error() )
+                return
+            }
+
+            p.Set( v , b )
+            return
+        }
+    }
+
+    err := json.Unmarshal( raw , &n )
+    if ( err != nil ) {
+        problem( p.Name , raw , "must be a whole number" )
+        return
+    }
+
+    if ( n < int( p.Min ) ) {
+        problem( p.Name , raw , p.range_msg() )
+        n = int( p.Min )
+    } else if ( n > int( p.Max ) ) {
+        problem( p.Name , raw , p.range_msg() )
+        n = int( p.Max )
+    }
+
+    p.Set( v , byte( n ) )
+}
+
+////////////////////////////////////////
+// Return the keys of a JSON object in sorted order, so that problems are
+// always reported in the same order.
+
+func sorted_keys( obj map[string]json.RawMessage ) []string {
+    var rv []string
+
+    for k := range obj {
+        rv = append( rv , k )
+    }
+    sort.Strings( rv )
+
+    return rv
+}
+
+///////////////////////////////////////////////////////////////////////////////
+//
+// Convert an error from the "encoding/json" package to a ParseError, with
+// the line and column where the problem was found.
+
+func json_error( err error , text []byte ) error {
+    pe := &ParseError{
+        Offset  : -1 ,
+        Msg     : err.Error() ,
+    }
+
+    offset := -1
+
+    if se , ok := err.( *json.SyntaxError ) ; ok {
+        offset = int( se.Offset )
+    } else if te , ok := err.( *json.UnmarshalTypeError ) ; ok {
+        offset = int( te.Offset )
+
+        what := "a voice object"
+        if ( te.Type.Kind() == reflect.Slice ) {
+            what = "a list of voices"
+        }
+        pe.Msg = fmt.Sprintf( "expected %s, found %s" , what , te.Value )
+    }
+
+    ////////////////////////////////////////
+    // The offset is just past the character (or value) where the problem
+    // was found.
+
+    if ( offset > 0 ) {
+        pe.Line , pe.Column = line_col( text , offset - 1 )
+    }
+
+    return pe
+}
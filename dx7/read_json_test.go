@@ -0,0 +1,76 @@
+// volca-convert - dx7/read_json_test.go
+// John Simpson <jms1@jms1.net> 2022-09-27
+//
+// Make sure problems in JSON files are reported the way we expect.
+
+package dx7
+
+import (
+    "strings"
+    "testing"
+)
+
+////////////////////////////////////////
+// Generate a JSON file containing one voice, with one line changed
+
+func json_with( old string , new string ) string {
+    text := GenerateJSON( Bank{ Voices: []Voice{ InitVoice() } } , true )
+    return strings.Replace( text , old , new , 1 )
+}
+
+func TestJSONProblems( t *testing.T ) {
+    tests := []struct {
+        text    string
+        want    string
+    } {
+        { json_with( `"DETU" :  7` , `"DETU" : 15` ) ,
+            `voice 1 "INIT VOICE" OP1.DETU=15: Detune must be 0..14` } ,
+        { json_with( `"EGR1" : 99` , `"EGR1" : 300` ) ,
+            `voice 1 "INIT VOICE" OP1.EGR1=300: EG Rate 1 must be 0..99` } ,
+        { json_with( `"ALGO" :  0` , `"ALGO" : "1"` ) ,
+            `voice 1 "INIT VOICE" ALGO="1": must be a whole number` } ,
+        { json_with( `"LFOR" : 35 ,` , `` ) ,
+            `voice 1 "INIT VOICE" LFOR: missing` } ,
+        { json_with( `"LFOR"` , `"LFOX"` ) ,
+            "voice 1 \"INIT VOICE\" LFOR: missing\n" +
+            `voice 1 "INIT VOICE" LFOX: unknown parameter` } ,
+        { json_with( `"EGR1"` , `"EGRX"` ) ,
+            "voice 1 \"INIT VOICE\" OP1.EGR1: missing\n" +
+            `voice 1 "INIT VOICE" OP1.EGRX: unknown parameter` } ,
+        { json_with( `"TRSP" : 24` , `"TRSP" : 24 , "XX09" : 1` ) ,
+            `voice 1 "INIT VOICE" ALL.XX09: unknown parameter` } ,
+        { json_with( `"NAME" : "INIT VOICE" ,` , `` ) ,
+            `voice 1 "" NAME: missing` } ,
+        { "[\n  {\n    \"NAME\" : \"X\" ,,\n" ,
+            `line 3 col 19: invalid character ',' looking for beginning of object key string` } ,
+        { `[ 1 ]` ,
+            `line 1 col 3: expected a voice object, found number` } ,
+    }
+
+    for _ , test := range tests {
+        _ , err := JSONCodec.Decode( strings.NewReader( test.text ) )
+        if ( err == nil ) {
+            t.Errorf( "no error, expected %s" , test.want )
+        } else if ( err.Error() != test.want ) {
+            t.Errorf( "got:\n%s\nexpected:\n%s" , err , test.want )
+        }
+    }
+}
+
+////////////////////////////////////////
+// Problem values are replaced with something valid
+
+func TestJSONRecovery( t *testing.T ) {
+    text := json_with( `"EGR1" : 99` , `"EGR1" : 300` )
+    text  = strings.Replace( text , `"LFOR" : 35 ,` , `` , 1 )
+
+    bank , err := JSONCodec.Decode( strings.NewReader( text ) )
+    if ( !Recoverable( err ) ) {
+        t.Fatalf( "expected a recoverable error, got %v" , err )
+    }
+
+    v := bank.Voices[0]
+    if ( ( v.OP[0].EGR1 != 99 ) || ( v.LFOR != 35 ) ) {
+        t.Errorf( "EGR1=%d LFOR=%d, expected 99 and 35" , v.OP[0].EGR1 , v.LFOR )
+    }
+}
@@ -0,0 +1,89 @@
+// volca-convert - dx7/read_raw.go
+// John Simpson <jms1@jms1.net> 2022-09-18
+//
+// Read a "raw" 32-voice bank - the 4096 bytes of voice data from a 32-voice
+// SYX file, without the SYSEX header, checksum, or end marker. This is the
+// format used by DX7 cartridge ROM images and some editors.
+
+package dx7
+
+import (
+    "fmt"
+    "io"
+    "io/ioutil"
+)
+
+///////////////////////////////////////////////////////////////////////////////
+//
+// RAW codec. This can only be read, use SYX to write the same data.
+
+type raw_codec struct{}
+
+var RawCodec Codec = raw_codec{}
+
+func init() {
+    Register( RawCodec )
+}
+
+func (raw_codec) Names() []string       { return []string{ "RAW" } }
+func (raw_codec) Description() string   { return "32 voices without SYSEX header (4096 bytes)" }
+func (raw_codec) Extensions() []string  { return nil }
+func (raw_codec) CanDecode() bool       { return true }
+func (raw_codec) CanEncode() bool       { return false }
+func (raw_codec) Binary() bool          { return true }
+
+////////////////////////////////////////
+// Exactly 4096 bytes, none of which have the high bit set (SYSEX data
+// bytes never do).
+//
+// Plenty of other files (i.e. text) could look like this, so this is only
+// checked if no other format matches, and only for files with one of the
+// extensions below (see FallbackSniffer). Other files have to use "-i RAW".
+
+func (raw_codec) SniffExtensions() []string { return []string{ ".bin" , ".rom" } }
+
+func (raw_codec) Sniff( head []byte ) bool {
+    if ( len( head ) != 4096 ) {
+        return false
+    }
+
+    for _ , b := range head {
+        if ( b > 0x7F ) {
+            return false
+        }
+    }
+
+    return true
+}
+
+///////////////////////////////////////////////////////////////////////////////
+
+func (raw_codec) Decode( r io.Reader ) ( Bank , error ) {
+    var bank Bank
+
+    data , err := ioutil.ReadAll( r )
+    if ( err != nil ) {
+        return bank , err
+    }
+
+    if ( len( data ) != 4096 ) {
+        return bank , &ParseError{
+            Offset  : len( data ) ,
+            Msg     : fmt.Sprintf( "raw bank must be 4096 bytes, not %d" , len( data ) ) ,
+        }
+    }
+
+    for n := 0 ; n < 32 ; n ++ {
+        v , err := ParseSYX128( data[ (128*n):(128*n+128) ] )
+        if ( err != nil ) {
+            return bank , err
+        }
+        bank.Voices = append( bank.Voices , v )
+    }
+
+    return bank , nil
+}
+
+func (raw_codec) Encode( io.Writer , Bank , Options ) error {
+    return ErrUnsupported
+}
@@ -0,0 +1,44 @@
+// volca-convert - dx7/read_raw_test.go
+// John Simpson <jms1@jms1.net> 2022-09-29
+//
+// Make sure RAW banks can always be written back out as SYX.
+
+package dx7
+
+import (
+    "bytes"
+    "testing"
+)
+
+func TestRawHighBit( t *testing.T ) {
+    bank := test_bank( 32 )
+    raw  := append( []byte{} , GenerateSYX128( bank )[6:4102]... )
+
+    ////////////////////////////////////////
+    // ROM images can have the high bit set in a name. The DX7 ignores it.
+
+    raw[ 128 * 4 + name128_loc ] |= 0x80
+
+    got , err := RawCodec.Decode( bytes.NewReader( raw ) )
+    if ( err != nil ) {
+        t.Fatal( err )
+    }
+
+    if ( got.Voices[4].Name != bank.Voices[4].Name ) {
+        t.Errorf( "got name %q, expected %q" , got.Voices[4].Name , bank.Voices[4].Name )
+    }
+
+    ////////////////////////////////////////
+    // The voices can be written as SYX and read back
+
+    again , err := SYXCodec.Decode( bytes.NewReader( GenerateSYX128( got ) ) )
+    if ( err != nil ) {
+        t.Fatalf( "reading the SYX output: %v" , err )
+    }
+
+    for n , v := range bank.Voices {
+        if ( again.Voices[n] != v ) {
+            t.Errorf( "voice %d is wrong" , n + 1 )
+        }
+    }
+}
@@ -0,0 +1,321 @@
+// volca-convert - dx7/read_syx.go
+// John Simpson <jms1@jms1.net> 2022-08-27
+//
+// Read a SYX file into memory.
+
+package dx7
+
+import (
+    "bytes"
+    "fmt"
+    "io"
+    "io/ioutil"
+)
+
+///////////////////////////////////////////////////////////////////////////////
+//
+// SYX codec. Encode() is in write_syx.go.
+
+type syx_codec struct{}
+
+var SYXCodec Codec = syx_codec{}
+
+func init() {
+    Register( SYXCodec )
+}
+
+func (syx_codec) Names() []string       { return []string{ "SYX" } }
+func (syx_codec) Description() string   { return "DX7 SYSEX dump (1 or 32 voices)" }
+func (syx_codec) Extensions() []string  { return []string{ ".syx" } }
+func (syx_codec) CanDecode() bool       { return true }
+func (syx_codec) CanEncode() bool       { return true }
+func (syx_codec) Binary() bool          { return true }
+
+////////////////////////////////////////
+// SYSEX message from Yamaha. It doesn't have to be at the very start of
+// the file, but there can't be anything except other SYSEX messages (or
+// padding) in front of it. Since every data byte in a SYSEX message is
+// below 0x80, text files will never contain the F0 43 sequence.
+
+func (syx_codec) Sniff( head []byte ) bool {
+    return bytes.Contains( head , []byte{ 0xF0 , 0x43 } )
+}
+
+///////////////////////////////////////////////////////////////////////////////
+//
+// Read a SYX file.
+//
+// Note that there are two kinds of SYX files, one containing a single voice
+// and one containing 32 voices (aka a "cartridge", since the cartridges on
+// the DX7 held 32 voices).
+//
+// - In files containing a single voice, every parameter within the voice
+//   uses a full byte, even if it only needs one bit. This means that the
+//   parameters use a total of 155 bytes.
+// - In files containing 32 voices, some of the parameters "share" bytes
+//   with others, so the final size of each voice is 128 bytes.
+// - Voice names can use any ASCII character, however the Volca FM/FM2 are
+//   only able to _show_ certain characters.
+//
+// The DX7 accepts dumps with any device number (0-15, which is the MIDI
+// channel minus one) in the header. The device number from the file is
+// stored in the Bank.
+//
+// A file may contain more than one SYSEX message. Every DX7 voice dump in
+// the file is read, in order, and anything else (other SYSEX messages, or
+// padding between messages) is skipped.
+//
+// If a message has the wrong (or no) checksum or end of message marker, or
+// if it was cut off before all of its voice data, the voices which could
+// be read are still returned, along with a *ChecksumError, *TruncatedError,
+// or (if there was more than one problem) an ErrorList of them. A message
+// is only "truncated" if voice data is missing.
+
+func ReadSYX( filename string ) ( Bank , error ) {
+    return ReadFile( filename , SYXCodec )
+}
+
+func (syx_codec) Decode( r io.Reader ) ( Bank , error ) {
+    var bank    Bank
+    var errs    ErrorList
+    var found   bool
+
+    ////////////////////////////////////////
+    // The SYX files we're dealing with aren't supposed to be larger than
+    // a few K, so it should be safe to read it all into memory at once.
+
+    buf , err := ioutil.ReadAll( r )
+    if ( err != nil ) {
+        return bank , err
+    }
+
+    ////////////////////////////////////////
+    // Process each SYSEX message
+
+    pos := 0
+    for {
+        start := bytes.IndexByte( buf[pos:] , 0xF0 )
+        if ( start < 0 ) {
+            break
+        }
+        start += pos
+
+        ////////////////////////////////////////
+        // The message ends at the next byte with the high bit set. This
+        // should be F7, but if the message was cut off it could be the F0
+        // of the next message, or the end of the file.
+
+        end := len( buf )
+        for n := start + 1 ; n < len( buf ) ; n ++ {
+            if ( buf[n] >= 0x80 ) {
+                end = n
+                break
+            }
+        }
+
+        msg := buf[start:end]
+        pos  = end
+        if ( ( end < len( buf ) ) && ( buf[end] == 0xF7 ) ) {
+            msg = buf[start:(end+1)]
+            pos = end + 1
+        }
+
+        ////////////////////////////////////////
+        // Skip anything which isn't a DX7 voice dump
+
+        size := syx_data_size( msg )
+        if ( size < 0 ) {
+            continue
+        }
+
+        ////////////////////////////////////////
+        // Remember the device number from the first voice dump
+
+        if ( !found ) {
+            bank.Device = msg[2] & 0x0F
+            found       = true
+        }
+
+        ////////////////////////////////////////
+        // Read the voices
+
+        voices , err := parse_syx_msg( msg , size , start , buf[pos:] )
+        bank.Voices = append( bank.Voices , voices... )
+        if ( err != nil ) {
+            if ( !Recoverable( err ) ) {
+                return bank , err
+            }
+            errs = append( errs , err )
+        }
+    }
+
+    ////////////////////////////////////////
+    // Make sure we found something
+
+    if ( !found ) {
+        msg := "no DX7 voice dump found"
+        if ( len( buf ) >= 6 ) {
+            msg = fmt.Sprintf( "not a recognized header: file='%s' SYX1='%s' SYX32='%s'" ,
+                bytes2hex( buf[0:6] ) , bytes2hex( SYX_h1 ) , bytes2hex( SYX_h32 ) )
+        }
+
+        return bank , &ParseError{
+            Offset  : 0 ,
+            Msg     : msg ,
+        }
+    }
+
+    ////////////////////////////////////////
+    // Return any problems which were found along the way
+
+    if ( len( errs ) == 1 ) {
+        return bank , errs[0]
+    } else if ( len( errs ) > 1 ) {
+        return bank , errs
+    }
+
+    return bank , nil
+}
+
+///////////////////////////////////////////////////////////////////////////////
+//
+// Read the voices from one SYSEX message. "msg" starts with the header and
+// ends with the F7 (if there is one), "size" is the size of the voice data,
+// "base" is the message's offset within the file, for error messages, and
+// "after" is whatever follows the message in the file.
+
+func parse_syx_msg( msg []byte , size int , base int , after []byte ) ( []Voice , error ) {
+    var rv []Voice
+
+    ////////////////////////////////////////
+    // If the message is too short to hold all of the voice data, but ended
+    // with F7, the byte before that was the checksum.
+
+    data := msg[6:]
+    if ( ( len( msg ) < size + 8 ) && ( len( data ) > 0 ) &&
+            ( data[ len( data ) - 1 ] == 0xF7 ) ) {
+        data = data[ :len( data ) - 1 ]
+        if ( len( data ) > 0 ) {
+            data = data[ :len( data ) - 1 ]
+        }
+    }
+
+    if ( len( data ) > size ) {
+        data = data[:size]
+    }
+
+    ////////////////////////////////////////
+    // Read whatever complete voices there are
+
+    if ( size == 155 ) {
+        if ( len( data ) == 155 ) {
+            v , err := ParseSYX155( data )
+            if ( err != nil ) {
+                return rv , err
+            }
+            rv = append( rv , v )
+        }
+    } else {
+        for n := 0 ; n + 128 <= len( data ) ; n += 128 {
+            v , err := ParseSYX128( data[n:(n+128)] )
+            if ( err != nil ) {
+                return rv , err
+            }
+            rv = append( rv , v )
+        }
+    }
+
+    ////////////////////////////////////////
+    // If some of the voice data is missing, the message was cut off
+
+    if ( len( data ) < size ) {
+        terr := &TruncatedError{
+            Offset      : base + len( msg ) ,
+            Voices      : len( rv ) ,
+            Expected    : 1 ,
+        }
+        if ( size == 4096 ) {
+            terr.Expected = 32
+        }
+
+        return rv , terr
+    }
+
+    ////////////////////////////////////////
+    // All of the voice data is there. Make sure the checksum and end marker
+    // are correct (or there at all). If not, the voices are still returned
+    // so the caller can decide whether to use them.
+
+    err := check_syx( msg , size , after )
+    if ( err != nil ) {
+        err.( *ChecksumError ).Offset += base
+    }
+
+    return rv , err
+}
+
+///////////////////////////////////////////////////////////////////////////////
+//
+// Parse one voice in the 155-byte (single voice) format. Every parameter
+// uses a full byte.
+
+func ParseSYX155( b []byte ) ( Voice , error ) {
+    var v Voice
+
+    if len( b ) < 155 {
+        return v , &ParseError{
+            Offset  : -1 ,
+            Msg     : fmt.Sprintf( "voice data (%d bytes) smaller than 155 bytes" ,
+                len( b ) ) ,
+        }
+    }
+
+    for _ , p := range Params {
+        if ( p.Off155 >= 0 ) {
+            p.Set( &v , b[ p.Off155 ] )
+        }
+    }
+
+    v.Name = syx_name( b[ name155_loc:(name155_loc+name_len) ] )
+
+    return v , nil
+}
+
+///////////////////////////////////////////////////////////////////////////////
+//
+// Parse one voice in the 128-byte (32 voice) format. Some parameters share
+// bytes with others, see params.go for the details.
+
+func ParseSYX128( b []byte ) ( Voice , error ) {
+    var v Voice
+
+    if len( b ) < 128 {
+        return v , &ParseError{
+            Offset  : -1 ,
+            Msg     : fmt.Sprintf( "voice data (%d bytes) smaller than 128 bytes" ,
+                len( b ) ) ,
+        }
+    }
+
+    for _ , p := range Params {
+        p.Set( &v , ( b[ p.Off128 ] >> p.Shift ) & p.Mask )
+    }
+
+    v.Name = syx_name( b[ name128_loc:(name128_loc+name_len) ] )
+
+    return v , nil
+}
+
+////////////////////////////////////////
+// Convert the name bytes to a string. The DX7 ignores the high bit of each
+// byte, so it's removed here. RAW files (which aren't SYSEX data) can have
+// it set, and it can't be written back out inside a SYX message.
+
+func syx_name( b []byte ) string {
+    rv := make( []byte , len( b ) )
+    for n , c := range b {
+        rv[n] = c & 0x7F
+    }
+
+    return string( rv )
+}
@@ -0,0 +1,81 @@
+// volca-convert - dx7/read_syx_test.go
+// John Simpson <jms1@jms1.net> 2022-09-27
+//
+// Make sure damaged SYX files are reported (and recovered) the way we expect.
+
+package dx7
+
+import (
+    "bytes"
+    "testing"
+)
+
+func TestSYXProblems( t *testing.T ) {
+    one  := GenerateSYX155( test_voice( 1 ) )
+    bank := GenerateSYX128( test_bank( 32 ) )
+
+    ////////////////////////////////////////
+    // Change a copy of a message
+
+    without_f7 := func( b []byte ) []byte {
+        return append( []byte{} , b[ :len( b ) - 1 ]... )
+    }
+
+    bad_checksum := func( b []byte ) []byte {
+        rv := append( []byte{} , b... )
+        rv[ len( rv ) - 2 ] ^= 0x01
+        return rv
+    }
+
+    tests := []struct {
+        name    string
+        data    []byte
+        voices  int
+        want    string
+    } {
+        { "one without F7" , without_f7( one ) , 1 ,
+            "byte 162: missing end of message marker: expected F7, found end of file" } ,
+        { "one without F7, then another" , append( without_f7( one ) , one... ) , 2 ,
+            "byte 162: missing end of message marker: expected F7, found F0" } ,
+        { "one without checksum or F7" , one[ :len( one ) - 2 ] , 1 ,
+            "byte 161: bad checksum: expected " + bytes2hex( one[161:162] ) +
+            ", found end of file" } ,
+        { "bank without F7" , without_f7( bank ) , 32 ,
+            "byte 4103: missing end of message marker: expected F7, found end of file" } ,
+        { "bank without F7, bad checksum" , without_f7( bad_checksum( bank ) ) , 32 ,
+            "byte 4102: bad checksum: expected " + bytes2hex( bank[4102:4103] ) +
+            ", found " + bytes2hex( []byte{ bank[4102] ^ 0x01 } ) } ,
+        { "bank cut off" , bank[ :1000 ] , 7 ,
+            "byte 1000: 32-voice dump is truncated, recovered 7 of 32 voices" } ,
+        { "bank cut off with F7" , append( append( []byte{} , bank[ :1000 ]... ) , 0x00 , 0xF7 ) , 7 ,
+            "byte 1002: 32-voice dump is truncated, recovered 7 of 32 voices" } ,
+        { "one cut off" , one[ :100 ] , 0 ,
+            "byte 100: 1-voice dump is truncated, recovered 0 of 1 voices" } ,
+    }
+
+    for _ , test := range tests {
+        got , err := SYXCodec.Decode( bytes.NewReader( test.data ) )
+        if ( err == nil ) {
+            t.Errorf( "%s: no error, expected %s" , test.name , test.want )
+            continue
+        } else if ( !Recoverable( err ) ) {
+            t.Errorf( "%s: error is not recoverable: %v" , test.name , err )
+        } else if ( err.Error() != test.want ) {
+            t.Errorf( "%s: got:\n%s\nexpected:\n%s" , test.name , err , test.want )
+        }
+
+        if ( len( got.Voices ) != test.voices ) {
+            t.Errorf( "%s: read %d voices, expected %d" , test.name , len( got.Voices ) , test.voices )
+        }
+    }
+
+    ////////////////////////////////////////
+    // The voices are the ones which were in the file
+
+    got , _ := SYXCodec.Decode( bytes.NewReader( without_f7( bank ) ) )
+    for n , v := range test_bank( 32 ).Voices {
+        if ( got.Voices[n] != v ) {
+            t.Errorf( "bank without F7: voice %d is wrong" , n + 1 )
+        }
+    }
+}
@@ -0,0 +1,191 @@
+// volca-convert - dx7/roundtrip_test.go
+// John Simpson <jms1@jms1.net> 2022-09-25
+//
+// Converting a SYX file to another format and back must produce exactly
+// the same bytes.
+
+package dx7
+
+import (
+    "bytes"
+    "testing"
+)
+
+///////////////////////////////////////////////////////////////////////////////
+//
+// SYX -> (codec) -> SYX for every format which can be read and written
+
+func TestRoundTrip( t *testing.T ) {
+    for name , syx := range test_syx_files() {
+        for _ , c := range Codecs() {
+            if ( !c.CanDecode() || !c.CanEncode() ) {
+                continue
+            }
+
+            for _ , opt := range []Options{ {} , { Simple: true } ,
+                    { Symbolic: true } , { Simple: true , Symbolic: true } ,
+                    { Roles: true } } {
+
+                bank , err := SYXCodec.Decode( bytes.NewReader( syx ) )
+                if ( err != nil ) {
+                    t.Fatalf( "%s: reading SYX: %v" , name , err )
+                }
+
+                var buf bytes.Buffer
+                err = c.Encode( &buf , bank , opt )
+                if ( err == ErrUnsupported ) {
+                    continue
+                } else if ( err != nil ) {
+                    t.Fatalf( "%s: writing %s: %v" , name , c.Names()[0] , err )
+                }
+
+                // simple CSV has no header, so it can't be read back
+                if ( ( c == CSVCodec ) && opt.Simple ) {
+                    continue
+                }
+
+                bank2 , err := c.Decode( bytes.NewReader( buf.Bytes() ) )
+                if ( err != nil ) {
+                    t.Fatalf( "%s: reading %s (%+v): %v" , name , c.Names()[0] , opt , err )
+                }
+
+                var out bytes.Buffer
+                err = SYXCodec.Encode( &out , bank2 , Options{} )
+                if ( err != nil ) {
+                    t.Fatalf( "%s: writing SYX from %s: %v" , name , c.Names()[0] , err )
+                }
+
+                if ( !bytes.Equal( out.Bytes() , syx ) ) {
+                    t.Errorf( "%s: SYX -> %s (%+v) -> SYX is not identical" ,
+                        name , c.Names()[0] , opt )
+                }
+            }
+        }
+    }
+}
+
+///////////////////////////////////////////////////////////////////////////////
+//
+// Packing a voice and parsing it again must return the same voice. This
+// checks that no two parameters overlap in the packed 128-byte format.
+
+func TestBitPacking( t *testing.T ) {
+    voices := []Voice{ test_voice_limit( false ) , test_voice_limit( true ) , InitVoice() }
+    for n := 1 ; n <= 64 ; n ++ {
+        voices = append( voices , test_voice( n ) )
+    }
+
+    ////////////////////////////////////////
+    // Each parameter by itself at its maximum value, so that a value
+    // "leaking" into a neighbouring parameter shows up.
+
+    for _ , p := range Params {
+        var v Voice
+        v.Name = "          "
+        p.Set( &v , p.Max )
+        voices = append( voices , v )
+    }
+
+    for _ , v := range voices {
+        b128 := make( []byte , 128 )
+        pack_syx128( v , b128 )
+
+        v2 , err := ParseSYX128( b128 )
+        if ( err != nil ) {
+            t.Fatalf( "ParseSYX128: %v" , err )
+        }
+        if ( v2 != v ) {
+            t.Errorf( "128-byte format: voice \"%s\" changed:\n  %+v\n  %+v" , v.Name , v , v2 )
+        }
+
+        for _ , b := range b128 {
+            if ( b > 0x7F ) {
+                t.Errorf( "128-byte format: voice \"%s\" has a byte with the high bit set" , v.Name )
+                break
+            }
+        }
+
+        ////////////////////////////////////////
+        // The 155-byte format has no room for the unused bits
+
+        v.XX08 , v.XX09 = 0 , 0
+        for o := range v.OP {
+            v.OP[o].XX11 , v.OP[o].XX13 , v.OP[o].XX15 = 0 , 0 , 0
+        }
+
+        b155 := make( []byte , 155 )
+        pack_syx155( v , b155 )
+
+        v2 , err = ParseSYX155( b155 )
+        if ( err != nil ) {
+            t.Fatalf( "ParseSYX155: %v" , err )
+        }
+        if ( v2 != v ) {
+            t.Errorf( "155-byte format: voice \"%s\" changed:\n  %+v\n  %+v" , v.Name , v , v2 )
+        }
+    }
+}
+
+///////////////////////////////////////////////////////////////////////////////
+//
+// Banks with 2-31 voices are padded, larger banks can be split
+
+func TestPadAndSplit( t *testing.T ) {
+    bank := test_bank( 40 )
+
+    parts := SplitBank( bank , 32 )
+    if ( ( len( parts ) != 2 ) || ( len( parts[0].Voices ) != 32 ) ||
+            ( len( parts[1].Voices ) != 8 ) ) {
+        t.Fatalf( "SplitBank returned the wrong sizes" )
+    }
+
+    pad := test_voice( 99 )
+
+    var buf bytes.Buffer
+    err := SYXCodec.Encode( &buf , parts[1] , Options{ Pad: &pad } )
+    if ( err != nil ) {
+        t.Fatalf( "writing 8 voices: %v" , err )
+    }
+
+    got , err := SYXCodec.Decode( &buf )
+    if ( err != nil ) {
+        t.Fatalf( "reading back: %v" , err )
+    }
+
+    if ( len( got.Voices ) != 32 ) {
+        t.Fatalf( "read back %d voices, expected 32" , len( got.Voices ) )
+    }
+
+    for n , v := range got.Voices {
+        want := pad
+        if ( n < 8 ) {
+            want = bank.Voices[ 32 + n ]
+        }
+
+        if ( v != want ) {
+            t.Errorf( "voice %d is wrong" , n + 1 )
+        }
+    }
+
+    ////////////////////////////////////////
+    // GenerateSYX128() pads short banks with INIT VOICE by itself
+
+    got , err = SYXCodec.Decode( bytes.NewReader( GenerateSYX128( parts[1] ) ) )
+    if ( err != nil ) {
+        t.Fatalf( "reading GenerateSYX128 output: %v" , err )
+    }
+
+    if ( ( len( got.Voices ) != 32 ) || ( got.Voices[7] != bank.Voices[39] ) ||
+            ( got.Voices[8] != InitVoice() ) ) {
+        t.Errorf( "GenerateSYX128 didn't pad the bank with INIT VOICE" )
+    }
+
+    ////////////////////////////////////////
+    // Too many voices for one file
+
+    buf.Reset()
+    err = SYXCodec.Encode( &buf , bank , Options{} )
+    if _ , ok := err.( *EncodeError ) ; !ok {
+        t.Errorf( "writing 40 voices: expected EncodeError, got %v" , err )
+    }
+}
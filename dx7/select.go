@@ -0,0 +1,136 @@
+// volca-convert - dx7/select.go
+// John Simpson <jms1@jms1.net> 2022-10-02
+//
+// Pick voices out of a bank.
+
+package dx7
+
+import (
+    "fmt"
+    "regexp"
+    "strconv"
+    "strings"
+)
+
+///////////////////////////////////////////////////////////////////////////////
+//
+// Return a copy of a bank containing only the voices listed in "spec", in
+// the order they're listed. The list is a comma-separated list of voice
+// numbers (starting at 1) and ranges, i.e. "1-8", "3,7-12", or "25-" (25
+// through the last voice).
+
+func SelectVoices( bank Bank , spec string ) ( Bank , error ) {
+    rv := bank
+    rv.Voices = nil
+
+    list , err := parse_voice_list( spec , len( bank.Voices ) )
+    if ( err != nil ) {
+        return rv , err
+    }
+
+    for _ , n := range list {
+        rv.Voices = append( rv.Voices , bank.Voices[n] )
+    }
+
+    return rv , nil
+}
+
+////////////////////////////////////////
+// Convert a list of voice numbers and ranges to a list of indexes into a
+// bank with "count" voices.
+
+func parse_voice_list( spec string , count int ) ( []int , error ) {
+    var rv []int
+
+    num := func( s string , item string ) ( int , error ) {
+        n , err := strconv.Atoi( strings.TrimSpace( s ) )
+        if ( ( err != nil ) || ( n < 1 ) ) {
+            return 0 , fmt.Errorf( "invalid voice number or range \"%s\"" , item )
+        }
+        if ( n > count ) {
+            return 0 , fmt.Errorf( "voice %d doesn't exist, there are only %d voices" ,
+                n , count )
+        }
+        return n , nil
+    }
+
+    for _ , item := range strings.Split( spec , "," ) {
+        first , last , is_range := strings.Cut( item , "-" )
+
+        a , err := num( first , item )
+        if ( err != nil ) {
+            return nil , err
+        }
+
+        b := a
+        if ( is_range ) {
+            b = count
+            if ( strings.TrimSpace( last ) != "" ) {
+                b , err = num( last , item )
+                if ( err != nil ) {
+                    return nil , err
+                }
+            }
+        }
+
+        if ( b < a ) {
+            return nil , fmt.Errorf( "invalid voice range \"%s\" (first voice is after last)" ,
+                item )
+        }
+
+        for n := a ; n <= b ; n ++ {
+            rv = append( rv , n - 1 )
+        }
+    }
+
+    return rv , nil
+}
+
+///////////////////////////////////////////////////////////////////////////////
+//
+// Return a copy of a bank containing only the voices whose names match a
+// pattern. Upper/lower case doesn't matter, and spaces at the end of the
+// names are ignored.
+//
+// If "regex" is false, the pattern is a "glob" where '*' matches any number
+// of characters and '?' matches any one character, and must match the whole
+// name. Otherwise it's a regular expression (see the "regexp" package),
+// which can match any part of the name.
+
+func SelectNames( bank Bank , pattern string , regex bool ) ( Bank , error ) {
+    rv := bank
+    rv.Voices = nil
+
+    if ( !regex ) {
+        pattern = glob_regex( pattern )
+    }
+
+    ////////////////////////////////////////
+    // Check the pattern by itself first, so the error message shows the
+    // pattern the way the caller wrote it.
+
+    if _ , err := regexp.Compile( pattern ) ; err != nil {
+        return rv , fmt.Errorf( "invalid name pattern: %s" , err )
+    }
+
+    re := regexp.MustCompile( "(?i)" + pattern )
+
+    for _ , v := range bank.Voices {
+        if ( re.MatchString( strings.TrimRight( v.Name , " " ) ) ) {
+            rv.Voices = append( rv.Voices , v )
+        }
+    }
+
+    return rv , nil
+}
+
+////////////////////////////////////////
+// Convert a glob pattern to a regular expression matching the whole string
+
+func glob_regex( glob string ) string {
+    rv := regexp.QuoteMeta( glob )
+    rv  = strings.ReplaceAll( rv , `\*` , ".*" )
+    rv  = strings.ReplaceAll( rv , `\?` , "." )
+
+    return "^" + rv + "$"
+}
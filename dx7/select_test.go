@@ -0,0 +1,94 @@
+// volca-convert - dx7/select_test.go
+// John Simpson <jms1@jms1.net> 2022-10-02
+
+package dx7
+
+import (
+    "reflect"
+    "testing"
+)
+
+func TestSelectVoices( t *testing.T ) {
+    bank := test_bank( 10 )
+
+    good := map[string][]int{
+        "1"         : { 1 } ,
+        "1-3"       : { 1 , 2 , 3 } ,
+        "3,7-9"     : { 3 , 7 , 8 , 9 } ,
+        "8-"        : { 8 , 9 , 10 } ,
+        "5,1,5"     : { 5 , 1 , 5 } ,
+        " 2 - 3 "   : { 2 , 3 } ,
+    }
+
+    for spec , want := range good {
+        got , err := SelectVoices( bank , spec )
+        if ( err != nil ) {
+            t.Errorf( "%q: %v" , spec , err )
+            continue
+        }
+
+        var expect []Voice
+        for _ , n := range want {
+            expect = append( expect , bank.Voices[ n - 1 ] )
+        }
+
+        if ( !reflect.DeepEqual( got.Voices , expect ) ) {
+            t.Errorf( "%q: wrong voices selected" , spec )
+        }
+    }
+
+    for _ , spec := range []string{ "" , "0" , "11" , "3-2" , "x" , "1,,2" , "-3" , "2-12" } {
+        if _ , err := SelectVoices( bank , spec ) ; err == nil {
+            t.Errorf( "%q: expected an error" , spec )
+        }
+    }
+}
+
+func TestSelectNames( t *testing.T ) {
+    var bank Bank
+
+    for _ , name := range []string{ "BRASS   1 " , "Brass 2   " , "E.PIANO 1 " , "BASS*     " , "SYN-BRASS " } {
+        v := InitVoice()
+        v.Name = name
+        bank.Voices = append( bank.Voices , v )
+    }
+
+    tests := []struct {
+        pattern string
+        regex   bool
+        want    []int
+    }{
+        { "BRASS*"      , false , []int{ 0 , 1 } } ,
+        { "brass ?"     , false , []int{ 1 } } ,
+        { "*BRASS*"     , false , []int{ 0 , 1 , 4 } } ,
+        { "E.PIANO 1"   , false , []int{ 2 } } ,
+        { "E?PIANO*"    , false , []int{ 2 } } ,
+        { "BASS*"       , false , []int{ 3 } } ,
+        { "PIANO"       , false , nil } ,
+        { "BRASS"       , true  , []int{ 0 , 1 , 4 } } ,
+        { "^B(R|A)"     , true  , []int{ 0 , 1 , 3 } } ,
+        { "[0-9]$"      , true  , []int{ 0 , 1 , 2 } } ,
+    }
+
+    for _ , test := range tests {
+        got , err := SelectNames( bank , test.pattern , test.regex )
+        if ( err != nil ) {
+            t.Errorf( "%q: %v" , test.pattern , err )
+            continue
+        }
+
+        var expect []Voice
+        for _ , n := range test.want {
+            expect = append( expect , bank.Voices[n] )
+        }
+
+        if ( !reflect.DeepEqual( got.Voices , expect ) ) {
+            t.Errorf( "%q: selected %d voices, expected %d" , test.pattern ,
+                len( got.Voices ) , len( expect ) )
+        }
+    }
+
+    if _ , err := SelectNames( bank , "(" , true ) ; err == nil {
+        t.Errorf( "expected an error for an invalid regex" )
+    }
+}
@@ -0,0 +1,83 @@
+// volca-convert - dx7/util.go
+// John Simpson <jms1@jms1.net> 2022-08-27
+//
+// Utility functions
+
+package dx7
+
+import (
+    "fmt"
+)
+
+///////////////////////////////////////////////////////////////////////////////
+//
+// Convert a []byte to a string of hex values. Useful for debug messages.
+
+func bytes2hex( b []byte ) string {
+    rv := ""
+
+    for i , c := range b {
+        if ( i > 0 ) {
+            rv += " "
+        }
+        rv += fmt.Sprintf( "%02X" , c )
+    }
+
+    return rv
+}
+
+///////////////////////////////////////////////////////////////////////////////
+//
+// Convert a string to a string of hex values. Useful for debug messages.
+
+func string2hex( s string ) string {
+    rv := ""
+
+    for i , c := range []byte( s ) {
+        if ( i > 0 ) {
+            rv += " "
+        }
+        rv += fmt.Sprintf( "%02X" , c )
+    }
+
+    return rv
+}
+
+///////////////////////////////////////////////////////////////////////////////
+//
+// Convert a byte offset within a text file to a line and column number
+// (both starting at 1).
+
+func line_col( text []byte , offset int ) ( int , int ) {
+    if ( offset > len( text ) ) {
+        offset = len( text )
+    }
+
+    line := 1
+    col  := 1
+
+    for _ , c := range text[:offset] {
+        if ( c == '\n' ) {
+            line ++
+            col = 1
+        } else {
+            col ++
+        }
+    }
+
+    return line , col
+}
+
+///////////////////////////////////////////////////////////////////////////////
+//
+// Return true if a list of strings contains a given string
+
+func contains( list []string , s string ) bool {
+    for _ , x := range list {
+        if ( x == s ) {
+            return true
+        }
+    }
+
+    return false
+}
@@ -0,0 +1,309 @@
+// volca-convert - dx7/values.go
+// John Simpson <jms1@jms1.net> 2022-10-04
+//
+// What parameter values mean, in the terms the DX7 manual uses.
+
+package dx7
+
+import (
+    "fmt"
+    "math"
+    "strconv"
+    "strings"
+)
+
+///////////////////////////////////////////////////////////////////////////////
+//
+// Names for parameters which select from a list
+
+var CurveNames = []string{ "-LIN" , "-EXP" , "+EXP" , "+LIN" }
+
+var LFOWaveNames = []string{ "TRIANGLE" , "SAW DOWN" , "SAW UP" , "SQUARE" ,
+    "SINE" , "S/HOLD" }
+
+var note_names = []string{ "C" , "C#" , "D" , "D#" , "E" , "F" , "F#" , "G" ,
+    "G#" , "A" , "A#" , "B" }
+
+////////////////////////////////////////
+// Return a name from one of the lists, or the number itself (with a '?')
+// if it's outside of the list.
+
+func list_name( list []string , n byte ) string {
+    if ( int( n ) < len( list ) ) {
+        return list[n]
+    }
+
+    return fmt.Sprintf( "?%d" , n )
+}
+
+func on_off( n byte ) string {
+    if ( n != 0 ) {
+        return "ON"
+    }
+
+    return "OFF"
+}
+
+///////////////////////////////////////////////////////////////////////////////
+//
+// Name of a MIDI note number, using Yamaha's octave numbers (middle C,
+// note 60, is "C3").
+
+func NoteName( note int ) string {
+    octave := note / 12 - 2
+    if ( note < 0 ) {
+        octave = ( note - 11 ) / 12 - 2
+    }
+
+    return fmt.Sprintf( "%s%d" , note_names[ ( ( note % 12 ) + 12 ) % 12 ] , octave )
+}
+
+////////////////////////////////////////
+// The note where an operator's keyboard level scaling changes direction.
+// LSBP 0 is A-1 (MIDI note 21), and LSBP 39 is C3.
+
+func BreakPoint( o *Operator ) int {
+    return int( o.LSBP ) + 21
+}
+
+////////////////////////////////////////
+// The note which sounds when middle C is played. TRSP 24 (no transpose)
+// is C3.
+
+func Transpose( v *Voice ) int {
+    return int( v.ALL.TRSP ) + 36
+}
+
+///////////////////////////////////////////////////////////////////////////////
+//
+// An operator's frequency. If "fixed" is true this is in Hz, otherwise it's
+// a ratio to the frequency of the note being played.
+//
+// - Ratio: coarse 0 is 0.5, 1-31 are themselves, and fine adds 1% each.
+// - Fixed: coarse selects 1, 10, 100, or 1000 Hz (only the lowest two bits
+//   are used), and each step of fine multiplies that by 10^(1/100).
+
+func Frequency( o *Operator ) ( freq float64 , fixed bool ) {
+    if ( o.OSCM != 0 ) {
+        return math.Pow( 10 , float64( o.FREC & 3 ) + float64( o.FREF ) / 100 ) , true
+    }
+
+    coarse := float64( o.FREC )
+    if ( o.FREC == 0 ) {
+        coarse = 0.5
+    }
+
+    return coarse * ( 1 + float64( o.FREF ) / 100 ) , false
+}
+
+////////////////////////////////////////
+// Detune is stored as 0-14, meaning -7 to +7
+
+func Detune( o *Operator ) int {
+    return int( o.DETU ) - 7
+}
+
+///////////////////////////////////////////////////////////////////////////////
+//
+// Describe the values of the top-level parameters, one operator, or the
+// "ALL" parameters, the way the DX7 shows them. These are used by the TEXT
+// writer.
+//
+//     ALGO #32
+//     RATIO x1.00  DETU +0  LSBP C3  LSLC -LIN  LSRC -LIN
+//     FIXED 1000 Hz  DETU +7  LSBP A-1  LSLC -EXP  LSRC +LIN
+//     OKS ON  LFOK OFF  LFOW TRIANGLE  TRSP C3
+
+func describe_op( o *Operator ) string {
+    var rv string
+
+    freq , fixed := Frequency( o )
+    if ( fixed ) {
+        rv = "FIXED " + format_hz( freq )
+    } else {
+        rv = fmt.Sprintf( "RATIO x%.2f" , freq )
+    }
+
+    return rv + fmt.Sprintf( "  DETU %+d  LSBP %s  LSLC %s  LSRC %s" ,
+        Detune( o ) , NoteName( BreakPoint( o ) ) ,
+        list_name( CurveNames , o.LSLC ) , list_name( CurveNames , o.LSRC ) )
+}
+
+func describe_voice( v *Voice ) string {
+    return fmt.Sprintf( "ALGO #%d" , int( v.ALGO ) + 1 )
+}
+
+func describe_all( v *Voice ) string {
+    return fmt.Sprintf( "OKS %s  LFOK %s  LFOW %s  TRSP %s" ,
+        on_off( v.ALL.OKS ) , on_off( v.ALL.LFOK ) ,
+        list_name( LFOWaveNames , v.ALL.LFOW ) , NoteName( Transpose( v ) ) )
+}
+
+////////////////////////////////////////
+// Show a frequency with four significant digits, i.e. "1.000 Hz" or
+// "1585 Hz".
+
+func format_hz( f float64 ) string {
+    digits := 3
+    for x := f ; ( x >= 10 ) && ( digits > 0 ) ; x /= 10 {
+        digits --
+    }
+
+    return fmt.Sprintf( "%.*f Hz" , digits , f )
+}
+
+///////////////////////////////////////////////////////////////////////////////
+//
+// Symbolic values, used by the "symbolic" dialect of JSON and CSV files.
+//
+// - LSLC, LSRC, LFOW, OSCM, OKS, and LFOK use names, i.e. "-EXP" or "SINE"
+// - LSBP and TRSP use note names, i.e. "C3"
+// - DETU uses -7 to +7, written with a sign (i.e. "+3") so that it can't
+//   be mistaken for the number stored in the file
+//
+// Other parameters don't have a symbolic form.
+//
+// A file is read as a symbolic file if any parameter other than DETU uses
+// its symbolic form. DETU is left out because a signed value (i.e. "-3")
+// means the same thing in either kind of file. An unsigned DETU is the
+// number stored in the file, except in symbolic files, where it could
+// also mean -7..+7. JSON numbers are read as -7..+7 in that case, and CSV
+// cells (which can't tell a number from a name) must have a sign.
+
+var osc_mode_names  = []string{ "RATIO" , "FIXED" }
+var on_off_names    = []string{ "OFF" , "ON" }
+
+func symbol_names( p *Param ) []string {
+    switch ( p.Field ) {
+        case "LSLC" , "LSRC":
+            return CurveNames
+        case "LFOW":
+            return LFOWaveNames
+        case "OSCM":
+            return osc_mode_names
+        case "OKS" , "LFOK":
+            return on_off_names
+    }
+
+    return nil
+}
+
+////////////////////////////////////////
+// MIDI note for a value of 0, for parameters which are notes
+
+func symbol_note( p *Param ) ( int , bool ) {
+    switch ( p.Field ) {
+        case "LSBP":
+            return 21 , true
+        case "TRSP":
+            return 36 , true
+    }
+
+    return 0 , false
+}
+
+////////////////////////////////////////
+// Return true if a parameter has a symbolic form
+
+func (p *Param) Symbolic() bool {
+    _ , note := symbol_note( p )
+    return ( symbol_names( p ) != nil ) || note || ( p.Field == "DETU" )
+}
+
+////////////////////////////////////////
+// Return the symbolic form of a value, or "" if the parameter doesn't have
+// one (or the value is outside of the parameter's range).
+
+func (p *Param) Symbol( n byte ) string {
+    if ( ( n < p.Min ) || ( n > p.Max ) ) {
+        return ""
+    }
+
+    if names := symbol_names( p ) ; names != nil {
+        return names[n]
+    }
+
+    if base , ok := symbol_note( p ) ; ok {
+        return NoteName( base + int( n ) )
+    }
+
+    if ( p.Field == "DETU" ) {
+        return fmt.Sprintf( "%+d" , int( n ) - 7 )
+    }
+
+    return ""
+}
+
+////////////////////////////////////////
+// Convert a symbolic value back to the number stored in the file. Upper and
+// lower case don't matter.
+
+func (p *Param) ParseSymbol( s string ) ( byte , error ) {
+    s = strings.ToUpper( strings.TrimSpace( s ) )
+
+    if names := symbol_names( p ) ; names != nil {
+        for n , name := range names {
+            if ( s == name ) {
+                return byte( n ) , nil
+            }
+        }
+
+        return 0 , fmt.Errorf( "must be one of %s" , strings.Join( names , ", " ) )
+    }
+
+    if base , ok := symbol_note( p ) ; ok {
+        note , ok := parse_note( s )
+        if ( !ok || ( note < base + int( p.Min ) ) || ( note > base + int( p.Max ) ) ) {
+            return 0 , fmt.Errorf( "must be a note from %s to %s" ,
+                NoteName( base + int( p.Min ) ) , NoteName( base + int( p.Max ) ) )
+        }
+
+        return byte( note - base ) , nil
+    }
+
+    if ( p.Field == "DETU" ) {
+        n , err := strconv.Atoi( s )
+        if ( ( err != nil ) || ( n < -7 ) || ( n > 7 ) || !signed( s ) ) {
+            return 0 , fmt.Errorf( "must be -7..+7, with a sign (i.e. \"+3\")" )
+        }
+
+        return byte( n + 7 ) , nil
+    }
+
+    return 0 , fmt.Errorf( "must be a number" )
+}
+
+////////////////////////////////////////
+// Return true if a string starts with a sign
+
+func signed( s string ) bool {
+    return strings.HasPrefix( s , "+" ) || strings.HasPrefix( s , "-" )
+}
+
+////////////////////////////////////////
+// Parse a note name (i.e. "C3", "F#-1") to a MIDI note number. The name
+// must already be upper case.
+
+func parse_note( s string ) ( int , bool ) {
+    if ( len( s ) < 2 ) {
+        return 0 , false
+    }
+
+    name := s[:1]
+    if ( s[1] == '#' ) {
+        name = s[:2]
+    }
+
+    octave , err := strconv.Atoi( s[ len( name ): ] )
+    if ( err != nil ) {
+        return 0 , false
+    }
+
+    for n , x := range note_names {
+        if ( x == name ) {
+            return ( octave + 2 ) * 12 + n , true
+        }
+    }
+
+    return 0 , false
+}
@@ -0,0 +1,186 @@
+// volca-convert - dx7/values_test.go
+// John Simpson <jms1@jms1.net> 2022-10-04
+
+package dx7
+
+import (
+    "strings"
+    "testing"
+)
+
+func TestValueNames( t *testing.T ) {
+    notes := map[int]string{
+        21  : "A-1" ,
+        60  : "C3" ,
+        61  : "C#3" ,
+        120 : "C8" ,
+        0   : "C-2" ,
+    }
+
+    for n , want := range notes {
+        if got := NoteName( n ) ; got != want {
+            t.Errorf( "NoteName(%d) = %s, expected %s" , n , got , want )
+        }
+    }
+
+    var o Operator
+
+    ops := []struct {
+        oscm , frec , fref  byte
+        want                string
+    }{
+        { 0 ,  1 ,  0 , "RATIO x1.00" } ,
+        { 0 ,  0 ,  0 , "RATIO x0.50" } ,
+        { 0 ,  0 , 50 , "RATIO x0.75" } ,
+        { 0 , 16 , 12 , "RATIO x17.92" } ,
+        { 1 ,  0 ,  0 , "FIXED 1.000 Hz" } ,
+        { 1 ,  2 ,  0 , "FIXED 100.0 Hz" } ,
+        { 1 ,  7 , 20 , "FIXED 1585 Hz" } ,
+        { 1 ,  3 , 99 , "FIXED 9772 Hz" } ,
+    }
+
+    for _ , x := range ops {
+        o.OSCM , o.FREC , o.FREF = x.oscm , x.frec , x.fref
+        o.DETU , o.LSBP , o.LSLC , o.LSRC = 7 , 39 , 0 , 3
+
+        want := x.want + "  DETU +0  LSBP C3  LSLC -LIN  LSRC +LIN"
+        if got := describe_op( &o ) ; got != want {
+            t.Errorf( "got %q, expected %q" , got , want )
+        }
+    }
+
+    v := InitVoice()
+    if got := describe_all( &v ) ; got != "OKS ON  LFOK ON  LFOW TRIANGLE  TRSP C3" {
+        t.Errorf( "INIT VOICE: got %q" , got )
+    }
+
+    v.ALL.LFOW = 7
+    v.ALL.TRSP = 0
+    v.ALL.OKS  = 0
+    if got := describe_all( &v ) ; got != "OKS OFF  LFOK ON  LFOW ?7  TRSP C1" {
+        t.Errorf( "got %q" , got )
+    }
+}
+
+func TestSymbols( t *testing.T ) {
+    ////////////////////////////////////////
+    // Every value of every symbolic parameter comes back the same
+
+    for _ , p := range Params {
+        if ( !p.Symbolic() ) {
+            if ( p.Symbol( p.Min ) != "" ) {
+                t.Errorf( "%s: not symbolic, but has a symbol" , p.Name )
+            }
+            continue
+        }
+
+        for n := int( p.Min ) ; n <= int( p.Max ) ; n ++ {
+            s := p.Symbol( byte( n ) )
+            got , err := p.ParseSymbol( s )
+            if ( ( err != nil ) || ( int( got ) != n ) ) {
+                t.Errorf( "%s: %d -> %q -> %d (%v)" , p.Name , n , s , got , err )
+            }
+        }
+
+        if ( p.Symbol( p.Max + 1 ) != "" ) {
+            t.Errorf( "%s: value out of range has a symbol" , p.Name )
+        }
+    }
+
+    ////////////////////////////////////////
+    // Input which isn't exactly what the writers generate
+
+    tests := []struct {
+        param   string
+        text    string
+        want    int
+    }{
+        { "ALL.LFOW"    , "sine"    , 4 } ,
+        { "ALL.LFOW"    , " S/HOLD" , 5 } ,
+        { "OP1.LSLC"    , "+exp"    , 2 } ,
+        { "OP1.OSCM"    , "Fixed"   , 1 } ,
+        { "ALL.TRSP"    , "c3"      , 24 } ,
+        { "OP2.LSBP"    , "a-1"     , 0 } ,
+        { "OP2.LSBP"    , "C#3"     , 40 } ,
+        { "OP3.DETU"    , "-3"      , 4 } ,
+        { "OP3.DETU"    , "+3"      , 10 } ,
+        { "OP3.DETU"    , "+0"      , 7 } ,
+        { "ALL.OKS"     , "on"      , 1 } ,
+        { "ALL.LFOW"    , "SQUAREWAVE" , -1 } ,
+        { "ALL.TRSP"    , "C6"      , -1 } ,
+        { "ALL.TRSP"    , "H3"      , -1 } ,
+        { "OP3.DETU"    , "+8"      , -1 } ,
+        { "OP3.DETU"    , "3"       , -1 } ,
+    }
+
+    for _ , test := range tests {
+        got , err := ParamByName( test.param ).ParseSymbol( test.text )
+        if ( test.want < 0 ) {
+            if ( err == nil ) {
+                t.Errorf( "%s %q: expected an error" , test.param , test.text )
+            }
+        } else if ( ( err != nil ) || ( int( got ) != test.want ) ) {
+            t.Errorf( "%s %q: got %d (%v), expected %d" , test.param , test.text ,
+                got , err , test.want )
+        }
+    }
+}
+
+///////////////////////////////////////////////////////////////////////////////
+//
+// In a file which uses symbolic values, DETU numbers are -7..+7 in JSON
+// and must have a sign in CSV, so "3" can't be mistaken for the number
+// stored in the file. A signed DETU doesn't make a file symbolic.
+
+func TestSymbolicDetune( t *testing.T ) {
+    bank := Bank{ Voices: []Voice{ InitVoice() } }
+
+    ////////////////////////////////////////
+    // Set OP1.DETU in a CSV file
+
+    csv_detu := func( opt Options , text string ) string {
+        return csv_with( opt , map[string]string{ "OP1.DETU" : text } )
+    }
+
+    json_sym := json_generate( bank , Options{ Symbolic: true } )
+    json_num := json_generate( bank , Options{} )
+
+    tests := []struct {
+        name    string
+        codec   Codec
+        text    string
+        want    int     // OP1.DETU, or -1 for an error
+    } {
+        { "JSON +3"         , JSONCodec , strings.Replace( json_sym , `"+0"` , `"+3"` , 1 ) , 10 } ,
+        { "JSON -3"         , JSONCodec , strings.Replace( json_sym , `"+0"` , `"-3"` , 1 ) , 4 } ,
+        { "JSON 3"          , JSONCodec , strings.Replace( json_sym , `"+0"` , `3` , 1 ) , 10 } ,
+        { "JSON -3 number"  , JSONCodec , strings.Replace( json_sym , `"+0"` , `-3` , 1 ) , 4 } ,
+        { "JSON 9 number"   , JSONCodec , strings.Replace( json_sym , `"+0"` , `9` , 1 ) , -1 } ,
+        { "JSON \"3\""      , JSONCodec , strings.Replace( json_sym , `"+0"` , `"3"` , 1 ) , -1 } ,
+        { "numeric JSON 3"  , JSONCodec , strings.Replace( json_num , `"DETU" :  7` , `"DETU" : 3` , 1 ) , 3 } ,
+        { "numeric JSON -3" , JSONCodec , strings.Replace( json_num , `"DETU" :  7` , `"DETU" : -3` , 1 ) , -1 } ,
+        { "numeric JSON \"-3\"" , JSONCodec , strings.Replace( json_num , `"DETU" :  7` , `"DETU" : "-3"` , 1 ) , 4 } ,
+        { "CSV +3"          , CSVCodec , csv_detu( Options{ Symbolic: true } , "+3" ) , 10 } ,
+        { "CSV -3"          , CSVCodec , csv_detu( Options{ Symbolic: true } , "-3" ) , 4 } ,
+        { "CSV 3"           , CSVCodec , csv_detu( Options{ Symbolic: true } , "3" ) , -1 } ,
+        { "numeric CSV 3"   , CSVCodec , csv_detu( Options{} , "3" ) , 3 } ,
+        { "numeric CSV -3"  , CSVCodec , csv_detu( Options{} , "-3" ) , 4 } ,
+        { "numeric CSV +3"  , CSVCodec , csv_detu( Options{} , "+3" ) , 10 } ,
+    }
+
+    for _ , test := range tests {
+        got , err := test.codec.Decode( strings.NewReader( test.text ) )
+        if ( test.want < 0 ) {
+            ve , ok := err.( *ValueError )
+            if ( err == nil ) {
+                t.Errorf( "%s: expected an error, got DETU=%d" , test.name , got.Voices[0].OP[0].DETU )
+            } else if ( !ok || ( ve.Param != "OP1.DETU" ) ) {
+                t.Errorf( "%s: expected one error for OP1.DETU, got %v" , test.name , err )
+            }
+        } else if ( err != nil ) {
+            t.Errorf( "%s: %v" , test.name , err )
+        } else if ( int( got.Voices[0].OP[0].DETU ) != test.want ) {
+            t.Errorf( "%s: got DETU=%d, expected %d" , test.name , got.Voices[0].OP[0].DETU , test.want )
+        }
+    }
+}
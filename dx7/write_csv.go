@@ -0,0 +1,147 @@
+// volca-convert - dx7/write_csv.go
+// John Simpson <jms1@jms1.net> 2022-08-27
+//
+// Write voices from memory to CSV file
+
+package dx7
+
+import (
+    "fmt"
+    "io"
+    "strings"
+)
+
+///////////////////////////////////////////////////////////////////////////////
+//
+// Create the CSV-safe version of a name, per RFC 4180 section 2
+// - double quotes are doubled
+// - characters outside of 20-7E are replaced with spaces
+
+func csv_safe_name( name string ) string {
+    var output strings.Builder
+
+    for _ , c := range name {
+        if ( c == '"' ) {
+            output.WriteString( "\"\"" )
+        } else if ( c < 0x20 ) {
+            output.WriteString( " " )
+        } else if ( c > 0x7E ) {
+            output.WriteString( " " )
+        } else {
+            output.WriteString( string( c ) )
+        }
+    }
+
+    return output.String()
+}
+
+///////////////////////////////////////////////////////////////////////////////
+//
+// Return the header rows for a CSV file without the unused bits
+
+func CSVHeader() string {
+    return csv_header( file_params() , false )
+}
+
+func csv_header( params []*Param , roles bool ) string {
+    h1 := ""
+    h2 := "\"NAME\""
+
+    for _ , p := range params {
+        if ( p.Group == "" ) {
+            h1 += ","
+        } else {
+            h1 += fmt.Sprintf( ",\"%s\"" , p.Group )
+        }
+        h2 += fmt.Sprintf( ",\"%s\"" , p.Field )
+    }
+
+    if ( roles ) {
+        for _ , g := range Groups[1:7] {
+            h1 += fmt.Sprintf( ",\"%s\"" , g )
+            h2 += ",\"ROLE\""
+        }
+    }
+
+    return( h1 + "\n" + h2 + "\n" )
+}
+
+///////////////////////////////////////////////////////////////////////////////
+//
+// Generate CSV output. If "with_header" is true, the output starts with the
+// two header rows.
+//
+// Deprecated: this can't use any of the other Options (such as Symbolic).
+// Use CSVCodec.Encode() instead.
+
+func GenerateCSV( bank Bank , with_header bool ) string {
+    return csv_generate( bank , Options{ Simple: !with_header } )
+}
+
+func csv_generate( bank Bank , opt Options ) string {
+    var output string
+
+    ////////////////////////////////////////
+    // If any voice has unused bits set, add columns for all of them to the
+    // end of every row, so that converting back to SYX gives the same bytes.
+    // The operators' roles (if requested) come after these.
+
+    params := file_params()
+
+    for _ , v := range bank.Voices {
+        if ( has_raw_bits( &v ) ) {
+            params = append( params , raw_params()... )
+            break
+        }
+    }
+
+    ////////////////////////////////////////
+    // If a header row was requested, start with that
+
+    if ( !opt.Simple ) {
+        output += csv_header( params , opt.Roles )
+    }
+
+    ////////////////////////////////////////
+    // Generate a row for each voice
+
+    for _, v := range bank.Voices {
+
+        output += fmt.Sprintf( "\"%s\"" , csv_safe_name( v.Name ) )
+
+        for _ , p := range params {
+            n := p.Get( &v )
+            if s := p.Symbol( n ) ; opt.Symbolic && ( s != "" ) {
+                output += fmt.Sprintf( ",\"%s\"" , s )
+            } else {
+                output += fmt.Sprintf( ",%d" , n )
+            }
+        }
+
+        ////////////////////////////////////////
+        // Each operator's role, if requested. The reader ignores these.
+
+        if ( opt.Roles ) {
+            for op := 1 ; op <= len( v.OP ) ; op ++ {
+                output += fmt.Sprintf( ",\"%s\"" , OperatorRole( &v , op ) )
+            }
+        }
+
+        output += "\n"
+    }
+
+    return output
+}
+
+///////////////////////////////////////////////////////////////////////////////
+//
+// Deprecated: use WriteFile() with CSVCodec, which takes Options.
+
+func WriteCSV( filename string , bank Bank , with_header bool ) error {
+    return WriteFile( filename , CSVCodec , bank , Options{ Simple: !with_header } )
+}
+
+func (csv_codec) Encode( w io.Writer , bank Bank , opt Options ) error {
+    _ , err := io.WriteString( w , csv_generate( bank , opt ) )
+    return err
+}
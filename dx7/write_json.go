@@ -0,0 +1,204 @@
+// volca-convert - dx7/write_json.go
+// John Simpson <jms1@jms1.net> 2022-08-27
+//
+// Write voices from memory to JSON file
+//
+// Note that I'm generating the output manually in order to ensure that the
+// parameters in the output appear in the same order that they do in the
+// Volca FM2.
+
+package dx7
+
+import (
+    "fmt"
+    "io"
+    "strings"
+)
+
+///////////////////////////////////////////////////////////////////////////////
+//
+// Create the JSON-safe version of a name, per RFC 8259 section 7 pp 1
+// - double quotes and backslashes must be escaped using backslash
+//   (or "reverse solidus", as the RFC calls it)
+// - characters outside of 20-7E are replaced with spaces
+
+func json_safe_name( name string ) string {
+    var output strings.Builder
+
+    for _ , c := range name {
+        if ( c == '\\' ) {
+            output.WriteString( "\\\\" )
+        } else if ( c == '"' ) {
+            output.WriteString( "\\\"" )
+        } else if ( c < 0x20 ) {
+            output.WriteString( " " )
+        } else if ( c > 0x7E ) {
+            output.WriteString( " " )
+        } else {
+            output.WriteString( string( c ) )
+        }
+    }
+
+    return output.String()
+}
+
+///////////////////////////////////////////////////////////////////////////////
+//
+// Generate JSON output. If "pretty" is true, the output is indented to make
+// it easier for humans to read and edit.
+//
+// Deprecated: this can't use any of the other Options (such as Symbolic).
+// Use JSONCodec.Encode() instead.
+
+func GenerateJSON( bank Bank , pretty bool ) string {
+    return json_generate( bank , Options{ Simple: !pretty } )
+}
+
+func json_generate( bank Bank , opt Options ) string {
+
+    var i_voice string
+    var i_vparm string
+    var i_oparm string
+    var nl      string
+    var sep     = ","
+    var f_num   = "%d"
+    var f_item  = "%s%s:%s"
+    var f_name  = "%s%s:\"%s\""
+    var f_grph  = "%s%s:{%s"
+
+    if ( !opt.Simple ) {
+        i_voice = "  "
+        i_vparm = "    "
+        i_oparm = "      "
+        nl      = "\n"
+        sep     = " ,\n"
+        f_num   = "%2d"
+        f_item  = "%s%-6s : %s"
+        f_name  = "%s%-6s : \"%s\""
+        f_grph  = "%s%-6s : {%s"
+    }
+
+    ////////////////////////////////////////
+    // Format one value. The symbolic dialect uses names for the parameters
+    // which have them, i.e. "LFOW" : "SINE".
+
+    value := func( p *Param , v *Voice ) string {
+        n := p.Get( v )
+        if ( opt.Symbolic ) {
+            if s := p.Symbol( n ) ; s != "" {
+                return "\"" + s + "\""
+            }
+        }
+        return fmt.Sprintf( f_num , n )
+    }
+
+    ////////////////////////////////////////
+    // Build objects for each voice
+
+    var vdata  []string
+
+    for _, v := range bank.Voices {
+
+        ////////////////////////////////////////
+        // Start list of voice parameters
+
+        var vparms []string
+
+        safe_name := json_safe_name( v.Name )
+
+        vparms = append( vparms , fmt.Sprintf( f_name , i_vparm , "\"NAME\"" , safe_name ) )
+
+        for _ , p := range GroupParams( "" ) {
+            qf := "\"" + p.Field + "\""
+            vparms = append( vparms , fmt.Sprintf( f_item , i_vparm , qf , value( p , &v ) ) )
+        }
+
+        ////////////////////////////////////////
+        // Build objects for each operator, and for "ALL"
+
+        for n , g := range Groups[1:] {
+            ////////////////////////////////////////
+            // Build list of parameters. Operators can start with their
+            // role, which is only for humans (the reader ignores it).
+
+            var pdata []string
+
+            if ( opt.Roles && ( n < len( v.OP ) ) ) {
+                if role := OperatorRole( &v , n + 1 ) ; role != "" {
+                    pdata = append( pdata , fmt.Sprintf( f_name , i_oparm , "\"ROLE\"" , role ) )
+                }
+            }
+
+            for _ , p := range GroupParams( g ) {
+                qf   := "\"" + p.Field + "\""
+                item := fmt.Sprintf( f_item , i_oparm , qf , value( p , &v ) )
+                pdata = append( pdata , item )
+            }
+
+            ////////////////////////////////////////
+            // Assemble the object for the group
+            // and add it to the list of voice parameters
+
+            gtext := fmt.Sprintf( f_grph , i_vparm , "\"" + g + "\"" , nl )
+            gtext += strings.Join( pdata , sep )
+            gtext += fmt.Sprintf( "%s%s}" , nl , i_vparm )
+
+            vparms = append( vparms , gtext )
+        }
+
+        ////////////////////////////////////////
+        // If any of the unused bits are set, add a "RAW" object with all
+        // of them, so that converting back to SYX gives the same bytes.
+
+        if ( has_raw_bits( &v ) ) {
+            var pdata []string
+
+            for _ , p := range raw_params() {
+                qn   := "\"" + p.Name + "\""
+                if ( !opt.Simple ) {
+                    qn = fmt.Sprintf( "%-10s" , qn )
+                }
+                item := fmt.Sprintf( f_item , i_oparm , qn , value( p , &v ) )
+                pdata = append( pdata , item )
+            }
+
+            gtext := fmt.Sprintf( f_grph , i_vparm , "\"RAW\"" , nl )
+            gtext += strings.Join( pdata , sep )
+            gtext += fmt.Sprintf( "%s%s}" , nl , i_vparm )
+
+            vparms = append( vparms , gtext )
+        }
+
+        ////////////////////////////////////////
+        // Build object for the voice
+
+        vtext := fmt.Sprintf( "%s{%s" , i_voice , nl )
+        vtext += strings.Join( vparms , sep )
+        vtext += fmt.Sprintf( "%s%s}" , nl , i_voice ) ;
+
+        ////////////////////////////////////////
+        // Add the voice to the list of voices
+
+        vdata = append( vdata , vtext )
+    }
+
+    if ( len( vdata ) < 1 ) {
+        return "[]\n"
+    }
+
+    return fmt.Sprintf( "[%s%s%s]\n" ,
+        nl , strings.Join( vdata , sep ) , nl )
+}
+
+///////////////////////////////////////////////////////////////////////////////
+//
+// Deprecated: use WriteFile() with JSONCodec, which takes Options.
+
+func WriteJSON( filename string , bank Bank , pretty bool ) error {
+    return WriteFile( filename , JSONCodec , bank , Options{ Simple: !pretty } )
+}
+
+func (json_codec) Encode( w io.Writer , bank Bank , opt Options ) error {
+    _ , err := io.WriteString( w , json_generate( bank , opt ) )
+    return err
+}
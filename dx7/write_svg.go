@@ -0,0 +1,269 @@
+// volca-convert - dx7/write_svg.go
+// John Simpson <jms1@jms1.net> 2022-10-06
+//
+// Write graphs of each voice's envelopes and keyboard scaling to an SVG
+// file. See envelope.go for how the graphs are calculated.
+
+package dx7
+
+import (
+    "fmt"
+    "html"
+    "io"
+    "strings"
+)
+
+///////////////////////////////////////////////////////////////////////////////
+//
+// SVG codec. This is only used for output.
+
+type svg_codec struct{}
+
+var SVGCodec Codec = svg_codec{}
+
+func init() {
+    Register( SVGCodec )
+}
+
+func (svg_codec) Names() []string       { return []string{ "SVG" } }
+func (svg_codec) Description() string   { return "graphs of the envelopes and keyboard scaling" }
+func (svg_codec) Extensions() []string  { return []string{ ".svg" } }
+func (svg_codec) Sniff( []byte ) bool   { return false }
+func (svg_codec) CanDecode() bool       { return false }
+func (svg_codec) CanEncode() bool       { return true }
+func (svg_codec) Binary() bool          { return false }
+
+func (svg_codec) Decode( io.Reader ) ( Bank , error ) {
+    return Bank{} , ErrUnsupported
+}
+
+///////////////////////////////////////////////////////////////////////////////
+//
+// Layout. Each voice is a block with its name at the top, then a row with
+// the six operator EGs, a row with the six keyboard scaling curves, and the
+// pitch EG.
+
+const (
+    svg_cell_w  = 150   // one graph, including its title and labels
+    svg_cell_h  = 100
+    svg_plot_w  = 140   // the graph itself
+    svg_plot_h  = 66
+    svg_title_h = 24    // voice name above the graphs
+    svg_voice_h = svg_title_h + 3 * svg_cell_h + 10
+    svg_margin  = 5
+
+    // Notes shown in the keyboard scaling graphs, A-1 to C8
+    svg_low_note    = 21
+    svg_high_note   = 120
+
+    // Middle C, used for the envelopes' rate scaling
+    svg_env_note    = 60
+)
+
+///////////////////////////////////////////////////////////////////////////////
+//
+// Generate an SVG file with graphs for every voice in a bank
+
+func GenerateSVG( bank Bank ) string {
+    var b strings.Builder
+
+    width  := 6 * svg_cell_w + 2 * svg_margin
+    height := len( bank.Voices ) * svg_voice_h + 2 * svg_margin
+
+    fmt.Fprintf( &b , "<svg xmlns=\"http://www.w3.org/2000/svg\" width=\"%d\" height=\"%d\" " +
+        "viewBox=\"0 0 %d %d\" font-family=\"monospace\" font-size=\"10\">\n" ,
+        width , height , width , height )
+    fmt.Fprintf( &b , "<rect width=\"100%%\" height=\"100%%\" fill=\"white\"/>\n" )
+
+    for n := range bank.Voices {
+        svg_voice( &b , &bank.Voices[n] , n + 1 ,
+            svg_margin , svg_margin + n * svg_voice_h )
+    }
+
+    b.WriteString( "</svg>\n" )
+
+    return b.String()
+}
+
+////////////////////////////////////////
+// One voice's block of graphs
+
+func svg_voice( b *strings.Builder , v *Voice , num int , x int , y int ) {
+    fmt.Fprintf( b , "<g id=\"voice-%d\">\n" , num )
+    fmt.Fprintf( b , "<text x=\"%d\" y=\"%d\" font-size=\"13\" font-weight=\"bold\">%d [%s]  ALGORITHM %d</text>\n" ,
+        x , y + 16 , num , svg_text( v.Name ) , int( v.ALGO ) + 1 )
+
+    for n := range v.OP {
+        o  := &v.OP[n]
+        cx := x + n * svg_cell_w
+
+        title := strings.TrimSpace( fmt.Sprintf( "OP%d %s" , n + 1 , OperatorRole( v , n + 1 ) ) )
+        svg_envelope( b , cx , y + svg_title_h , title , OperatorEnvelope( o , svg_env_note ) ,
+            0 , 4096 , "#1f5fbf" )
+
+        svg_scaling( b , o , n + 1 , cx , y + svg_title_h + svg_cell_h )
+    }
+
+    env := PitchEnvelope( v )
+    top := 1.0
+    for _ , p := range append( env.Held , env.Released... ) {
+        for ( ( p.Level > top ) || ( -p.Level > top ) ) && ( top < 48 ) {
+            top *= 2
+        }
+    }
+    svg_envelope( b , x , y + svg_title_h + 2 * svg_cell_h ,
+        fmt.Sprintf( "PITCH EG  +/-%g ST" , top ) ,
+        env , -top , top , "#2f8f2f" )
+
+    b.WriteString( "</g>\n" )
+}
+
+///////////////////////////////////////////////////////////////////////////////
+//
+// Draw one envelope. The first 60% of the width is the time the key is
+// held, then the level is held for a moment (10%), and the last 30% is
+// after the key is released (marked with a dashed line). The attack and
+// release times are shown below the graph, with a '+' if they were cut off
+// because they were too long.
+
+func svg_envelope( b *strings.Builder , x int , y int , title string ,
+    env Envelope , lo float64 , hi float64 , color string ) {
+
+    held , released := env.Held , env.Released
+
+    px , py := float64( x + 5 ) , float64( y + 16 )
+    pw , ph := float64( svg_plot_w ) , float64( svg_plot_h )
+
+    svg_frame( b , x , y , title )
+
+    ////////////////////////////////////////
+    // The zero line, for the pitch EG
+
+    if ( lo < 0 ) {
+        zy := py + ph / 2
+        fmt.Fprintf( b , "<line x1=\"%.1f\" y1=\"%.1f\" x2=\"%.1f\" y2=\"%.1f\" stroke=\"#cccccc\"/>\n" ,
+            px , zy , px + pw , zy )
+    }
+
+    ////////////////////////////////////////
+    // Scale the points to fit
+
+    t_held := held[ len( held ) - 1 ].Time
+    t_rel  := released[ len( released ) - 1 ].Time
+
+    fy := func( level float64 ) float64 {
+        return py + ph - ( level - lo ) / ( hi - lo ) * ph
+    }
+
+    var pts []string
+
+    for _ , p := range held {
+        fx := px
+        if ( t_held > 0 ) {
+            fx += p.Time / t_held * pw * 0.6
+        }
+        pts = append( pts , fmt.Sprintf( "%.1f,%.1f" , fx , fy( p.Level ) ) )
+    }
+
+    for _ , p := range released {
+        fx := px + pw * 0.7
+        if ( t_rel > 0 ) {
+            fx += p.Time / t_rel * pw * 0.3
+        }
+        pts = append( pts , fmt.Sprintf( "%.1f,%.1f" , fx , fy( p.Level ) ) )
+    }
+
+    fmt.Fprintf( b , "<polyline points=\"%s\" fill=\"none\" stroke=\"%s\" stroke-width=\"1.5\"/>\n" ,
+        strings.Join( pts , " " ) , color )
+
+    ////////////////////////////////////////
+    // Key released
+
+    kx := px + pw * 0.7
+    fmt.Fprintf( b , "<line x1=\"%.1f\" y1=\"%.1f\" x2=\"%.1f\" y2=\"%.1f\" stroke=\"#999999\" stroke-dasharray=\"2,2\"/>\n" ,
+        kx , py , kx , py + ph )
+
+    ////////////////////////////////////////
+    // Times
+
+    cut := func( c bool ) string {
+        if ( c ) {
+            return "+"
+        }
+        return ""
+    }
+
+    fmt.Fprintf( b , "<text x=\"%.1f\" y=\"%.1f\">%.2fs%s</text>\n" ,
+        px , py + ph + 11 , t_held , cut( env.HeldCut ) )
+    fmt.Fprintf( b , "<text x=\"%.1f\" y=\"%.1f\" text-anchor=\"end\">%.2fs%s</text>\n" ,
+        px + pw , py + ph + 11 , t_rel , cut( env.ReleasedCut ) )
+}
+
+///////////////////////////////////////////////////////////////////////////////
+//
+// Draw one operator's keyboard scaling: its output level (0-127, after
+// scaling) for every note from A-1 to C8, with the break point marked.
+
+func svg_scaling( b *strings.Builder , o *Operator , op int , x int , y int ) {
+    px , py := float64( x + 5 ) , float64( y + 16 )
+    pw , ph := float64( svg_plot_w ) , float64( svg_plot_h )
+
+    svg_frame( b , x , y , fmt.Sprintf( "OP%d SCALING  BP %s" , op , NoteName( BreakPoint( o ) ) ) )
+
+    fx := func( note int ) float64 {
+        return px + float64( note - svg_low_note ) / float64( svg_high_note - svg_low_note ) * pw
+    }
+
+    var pts []string
+    for note := svg_low_note ; note <= svg_high_note ; note ++ {
+        fy := py + ph - float64( ScaledLevel( o , note ) ) / 127 * ph
+        pts = append( pts , fmt.Sprintf( "%.1f,%.1f" , fx( note ) , fy ) )
+    }
+
+    fmt.Fprintf( b , "<polyline points=\"%s\" fill=\"none\" stroke=\"#bf6f1f\" stroke-width=\"1.5\"/>\n" ,
+        strings.Join( pts , " " ) )
+
+    ////////////////////////////////////////
+    // Break point
+
+    bx := fx( BreakPoint( o ) )
+    fmt.Fprintf( b , "<line x1=\"%.1f\" y1=\"%.1f\" x2=\"%.1f\" y2=\"%.1f\" stroke=\"#999999\" stroke-dasharray=\"2,2\"/>\n" ,
+        bx , py , bx , py + ph )
+
+    ////////////////////////////////////////
+    // Curves and depths
+
+    fmt.Fprintf( b , "<text x=\"%.1f\" y=\"%.1f\">%s %d</text>\n" , px , py + ph + 11 ,
+        list_name( CurveNames , o.LSLC ) , o.LSLD )
+    fmt.Fprintf( b , "<text x=\"%.1f\" y=\"%.1f\" text-anchor=\"end\">%s %d</text>\n" , px + pw , py + ph + 11 ,
+        list_name( CurveNames , o.LSRC ) , o.LSRD )
+}
+
+////////////////////////////////////////
+// Title and border for one graph
+
+func svg_frame( b *strings.Builder , x int , y int , title string ) {
+    fmt.Fprintf( b , "<text x=\"%d\" y=\"%d\">%s</text>\n" , x + 5 , y + 11 , svg_text( title ) )
+    fmt.Fprintf( b , "<rect x=\"%d\" y=\"%d\" width=\"%d\" height=\"%d\" fill=\"none\" stroke=\"#cccccc\"/>\n" ,
+        x + 5 , y + 16 , svg_plot_w , svg_plot_h )
+}
+
+////////////////////////////////////////
+// Make a string safe to use as text in an SVG file. Control characters
+// aren't allowed in XML at all, so they're replaced with spaces.
+
+func svg_text( s string ) string {
+    return html.EscapeString( strings.Map( func( c rune ) rune {
+        if ( c < 0x20 ) {
+            return ' '
+        }
+        return c
+    } , s ) )
+}
+
+///////////////////////////////////////////////////////////////////////////////
+
+func (svg_codec) Encode( w io.Writer , bank Bank , opt Options ) error {
+    _ , err := io.WriteString( w , GenerateSVG( bank ) )
+    return err
+}
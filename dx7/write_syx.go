@@ -0,0 +1,161 @@
+// volca-convert - dx7/write_syx.go
+// John Simpson <jms1@jms1.net> 2022-09-04
+//
+// Write voices from memory to SYX file
+
+package dx7
+
+import (
+    "fmt"
+    "io"
+)
+
+///////////////////////////////////////////////////////////////////////////////
+//
+// Generate the 155-byte data for one voice
+
+func pack_syx155( v Voice , output []byte ) {
+    for _ , p := range Params {
+        if ( p.Off155 >= 0 ) {
+            output[ p.Off155 ] = p.Get( &v )
+        }
+    }
+
+    ////////////////////////////////////////
+    // Add voice name.
+    // First add spaces in case v.Name is less than 10 bytes.
+
+    name := output[ name155_loc:(name155_loc+name_len) ]
+    copy( name , "          " )
+    copy( name , v.Name       )
+}
+
+///////////////////////////////////////////////////////////////////////////////
+//
+// Generate the 128-byte data for one voice. Parameters which share a byte
+// are masked so that an out-of-range value can't corrupt its neighbours.
+
+func pack_syx128( v Voice , output []byte ) {
+    for _ , p := range Params {
+        output[ p.Off128 ] |= ( p.Get( &v ) & p.Mask ) << p.Shift
+    }
+
+    name := output[ name128_loc:(name128_loc+name_len) ]
+    copy( name , "          " )
+    copy( name , v.Name       )
+}
+
+///////////////////////////////////////////////////////////////////////////////
+//
+// Generate SYX data for one voice
+
+func GenerateSYX155( v Voice ) []byte {
+    output := make( []byte , 163 )  // 6 + 155 + 1
+
+    ////////////////////////////////////////
+    // Start with header
+
+    copy( output[0:6] , SYX_h1 )
+
+    ////////////////////////////////////////
+    // Add voice data
+
+    pack_syx155( v , output[6:161] )
+
+    ////////////////////////////////////////
+    // Calculate and add checksum byte
+
+    output[161] = syx_checksum( output[6:161] )
+
+    ////////////////////////////////////////
+    // Add SYSEX end of message marker
+
+    output[162] = 0xF7
+
+    ////////////////////////////////////////
+    // fin
+
+    return output
+}
+
+///////////////////////////////////////////////////////////////////////////////
+//
+// Generate SYX data for 32 voices. Banks with fewer voices are padded with
+// INIT VOICE (use PadBank() first to pad with something else), and any
+// voices after the first 32 are ignored.
+
+func GenerateSYX128( bank Bank ) []byte {
+    output := make( []byte , 4104 ) // 6 + 4096 + 1
+
+    bank = PadBank( bank , 32 , nil )
+
+    ////////////////////////////////////////
+    // Start with header
+
+    copy( output[0:6] , SYX_h32 )
+
+    ////////////////////////////////////////
+    // Process voices
+
+    for vn := 0 ; vn < 32 ; vn ++ {
+        v_loc := 6 + 128 * vn
+        pack_syx128( bank.Voices[vn] , output[ v_loc:(v_loc+128) ] )
+    }
+
+    ////////////////////////////////////////
+    // Calculate and add checksum byte
+
+    output[4102] = syx_checksum( output[6:4102] )
+
+    ////////////////////////////////////////
+    // Add SYSEX end of message marker
+
+    output[4103] = 0xF7
+
+    ////////////////////////////////////////
+    // fin
+
+    return output
+}
+
+///////////////////////////////////////////////////////////////////////////////
+
+func WriteSYX( filename string , bank Bank ) error {
+    return WriteFile( filename , SYXCodec , bank , Options{} )
+}
+
+func (syx_codec) Encode( w io.Writer , bank Bank , opt Options ) error {
+    var contents []byte
+
+    ////////////////////////////////////////
+    // A single voice is written in the single-voice format. Anything up
+    // to 32 voices is written as a 32-voice bank, padded with opt.Pad.
+    // Larger banks need to be split (see SplitBank()).
+
+    nv := len( bank.Voices )
+    if ( nv == 1 ) {
+        contents = GenerateSYX155( bank.Voices[0] )
+    } else if ( ( nv > 1 ) && ( nv <= 32 ) ) {
+        contents = GenerateSYX128( PadBank( bank , 32 , opt.Pad ) )
+    } else {
+        return &EncodeError{
+            Format  : "SYX" ,
+            Msg     : fmt.Sprintf( "file must contain 1 to 32 voices, not %d" , nv ) ,
+        }
+    }
+
+    ////////////////////////////////////////
+    // Set the device number in the header. This isn't included in the
+    // checksum, so it can be changed after the data is generated.
+
+    contents[2] = bank.Device & 0x0F
+    if ( opt.Channel > 0 ) {
+        contents[2] = byte( opt.Channel - 1 )
+    }
+
+    ////////////////////////////////////////
+    // Do the deed
+
+    _ , err := w.Write( contents )
+    return err
+}
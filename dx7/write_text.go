@@ -0,0 +1,143 @@
+// volca-convert - dx7/write_text.go
+// John Simpson <jms1@jms1.net> 2022-08-27
+//
+// Write voices from memory to TEXT file
+
+package dx7
+
+import (
+    "fmt"
+    "io"
+    "strings"
+)
+
+///////////////////////////////////////////////////////////////////////////////
+//
+// TEXT codec. This is only used for output.
+
+type text_codec struct{}
+
+var TextCodec Codec = text_codec{}
+
+func init() {
+    Register( TextCodec )
+}
+
+func (text_codec) Names() []string       { return []string{ "TEXT" , "TXT" } }
+func (text_codec) Description() string   { return "human-readable dump of all parameters" }
+func (text_codec) Extensions() []string  { return []string{ ".txt" } }
+func (text_codec) Sniff( []byte ) bool   { return false }
+func (text_codec) CanDecode() bool       { return false }
+func (text_codec) CanEncode() bool       { return true }
+func (text_codec) Binary() bool          { return false }
+
+func (text_codec) Decode( io.Reader ) ( Bank , error ) {
+    return Bank{} , ErrUnsupported
+}
+
+///////////////////////////////////////////////////////////////////////////////
+//
+// Format one group of parameters, eight to a line, in two blocks of four.
+//
+//     EGR1 70  EGR2 40  EGR3 49  EGR4 99    EGL1 99  EGL2 92  EGL3  0  EGL4  0
+
+func text_params( v Voice , params []*Param ) string {
+    var output string
+
+    for i , p := range params {
+        if ( ( i % 4 ) == 0 ) {
+            output += "    "
+        } else {
+            output += "  "
+        }
+
+        output += fmt.Sprintf( "%-4s %2d" , p.Field , p.Get( &v ) )
+
+        if ( ( ( i % 8 ) == 7 ) || ( i == len( params ) - 1 ) ) {
+            output += "\n"
+        }
+    }
+
+    return output
+}
+
+///////////////////////////////////////////////////////////////////////////////
+//
+// Generate TEXT output. If "extras" is true, each voice's name is also
+// shown in hex.
+//
+// Deprecated: this can't use any of the other Options (such as
+// VerboseValues). Use TextCodec.Encode() instead.
+
+func GenerateText( bank Bank , extras bool ) string {
+    return text_generate( bank , Options{ Simple: !extras } )
+}
+
+func text_generate( bank Bank , opt Options ) string {
+    var output string
+
+    for i , v := range bank.Voices {
+        if ( i > 0 ) {
+            output += "\n"
+        }
+
+        output += fmt.Sprintf( "%-12s" , ( "[" + v.Name + "]" ) )
+
+        sep := " "
+        for _ , p := range GroupParams( "" ) {
+            output += fmt.Sprintf( "%s%s %2d" , sep , p.Field , p.Get( &v ) )
+            sep = "  "
+        }
+
+        if ( !opt.Simple ) {
+            output += fmt.Sprintf( "    NAME %s\n" , string2hex( v.Name ) )
+        } else {
+            output += "\n"
+        }
+
+        if ( opt.VerboseValues ) {
+            output += "    = " + describe_voice( &v ) + "\n"
+        }
+
+        if a := AlgorithmFor( v.ALGO ) ; ( a != nil ) && !opt.NoDiagram {
+            output += fmt.Sprintf( "  ALGORITHM %d  %s\n" , a.Number , a.Summary() )
+            for _ , line := range a.Diagram() {
+                output += "    " + line + "\n"
+            }
+        }
+
+        for n , g := range Groups[1:] {
+            if ( n < len( v.OP ) ) {
+                output += strings.TrimRight( fmt.Sprintf( "  %-5s%s" , g ,
+                    OperatorRole( &v , n + 1 ) ) , " " ) + "\n"
+            } else {
+                output += fmt.Sprintf( "  %s\n" , g )
+            }
+
+            output += text_params( v , GroupParams( g ) )
+
+            if ( opt.VerboseValues ) {
+                if ( n < len( v.OP ) ) {
+                    output += "    = " + describe_op( &v.OP[n] ) + "\n"
+                } else {
+                    output += "    = " + describe_all( &v ) + "\n"
+                }
+            }
+        }
+    }
+
+    return output
+}
+
+///////////////////////////////////////////////////////////////////////////////
+//
+// Deprecated: use WriteFile() with TextCodec, which takes Options.
+
+func WriteText( filename string , bank Bank , extras bool ) error {
+    return WriteFile( filename , TextCodec , bank , Options{ Simple: !extras } )
+}
+
+func (text_codec) Encode( w io.Writer , bank Bank , opt Options ) error {
+    _ , err := io.WriteString( w , text_generate( bank , opt ) )
+    return err
+}
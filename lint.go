@@ -0,0 +1,167 @@
+// volca-convert - lint.go
+// John Simpson <jms1@jms1.net> 2022-09-28
+//
+// The "lint" subcommand - check the voices in a file without converting
+// anything.
+
+package main
+
+import (
+    "encoding/json"
+    "flag"
+    "fmt"
+    "os"
+
+    "jms1.net/volca-convert/dx7"
+)
+
+///////////////////////////////////////////////////////////////////////////////
+//
+// usage
+
+const lint_usage = `volca-convert lint [options] INFILE
+
+Check every voice in INFILE for problems, such as parameter values which are
+outside of the range the DX7 allows, or names containing characters which the
+Volca FM/FM2 can't show. Problems with the file itself which still leave usable
+voices (such as a bad SYX checksum) are reported as well.
+
+The exit code is 0 if no problems were found, or 6 if there were problems.
+
+-i ___  Specify the type of INFILE, the same as when converting a file.
+
+--json  Write the report as JSON, for other programs to read.
+
+-v      Verbose. Show what kind of input file was detected.
+
+`
+
+func lint_usage_text() string {
+    return lint_usage
+}
+
+///////////////////////////////////////////////////////////////////////////////
+//
+// JSON report
+
+type lint_problem struct {
+    Voice   int     `json:"voice,omitempty"`
+    Name    string  `json:"name,omitempty"`
+    Param   string  `json:"param,omitempty"`
+    Value   string  `json:"value,omitempty"`
+    Message string  `json:"message"`
+}
+
+type lint_report struct {
+    File        string          `json:"file"`
+    Voices      int             `json:"voices"`
+    Problems    []lint_problem  `json:"problems"`
+}
+
+///////////////////////////////////////////////////////////////////////////////
+
+func lint_main( args []string ) {
+    var itype   string
+    var as_json bool
+    var verbose bool
+
+    flags := flag.NewFlagSet( "lint" , flag.ExitOnError )
+    flags.StringVar( &itype   , "i" , ""    , "input type" )
+    flags.BoolVar( &as_json   , "json" , false , "JSON report" )
+    flags.BoolVar( &verbose   , "v" , false , "verbose" )
+    command_usage = lint_usage_text
+    flags.Usage   = usage
+    flags.Parse( args )
+
+    infile := flags.Arg( 0 )
+    if ( infile == "" ) {
+        usage()
+    }
+
+    fname := infile
+    if ( fname == "-" ) {
+        fname = "(stdin)"
+    }
+
+    ////////////////////////////////////////
+    // Read the file. Problems which still leave usable voices are part of
+    // the report, anything else is a failure.
+
+    var problems []error
+
+    bank , err := dx7.ReadFile( infile , input_codec( infile , itype , verbose ) )
+    if ( err != nil ) {
+        if ( !dx7.Recoverable( err ) ) {
+            fail( err )
+        }
+
+        if list , ok := err.( dx7.ErrorList ) ; ok {
+            problems = append( problems , list... )
+        } else {
+            problems = append( problems , err )
+        }
+    }
+
+    ////////////////////////////////////////
+    // Check the voices
+
+    for _ , p := range dx7.Lint( bank ) {
+        p.File = fname
+        problems = append( problems , p )
+    }
+
+    ////////////////////////////////////////
+    // Report
+
+    if ( as_json ) {
+        report := lint_report{
+            File        : fname ,
+            Voices      : len( bank.Voices ) ,
+            Problems    : []lint_problem{} ,
+        }
+
+        for _ , p := range problems {
+            if ve , ok := p.( *dx7.ValueError ) ; ok {
+                report.Problems = append( report.Problems , lint_problem{
+                    Voice   : ve.Voice ,
+                    Name    : ve.Name ,
+                    Param   : ve.Param ,
+                    Value   : ve.Value ,
+                    Message : ve.Msg ,
+                } )
+            } else {
+                report.Problems = append( report.Problems , lint_problem{
+                    Message : p.Error() ,
+                } )
+            }
+        }
+
+        out , _ := json.MarshalIndent( report , "" , "  " )
+        fmt.Println( string( out ) )
+    } else {
+        for _ , p := range problems {
+            fmt.Println( p )
+        }
+
+        fmt.Printf( "\"%s\": %s, %s\n" , fname ,
+            plural( len( bank.Voices ) , "voice" ) ,
+            plural( len( problems ) , "problem" ) )
+    }
+
+    if ( len( problems ) > 0 ) {
+        os.Exit( EXIT_LINT )
+    }
+}
+
+////////////////////////////////////////
+// "1 voice", "2 voices", "no problems"
+
+func plural( n int , what string ) string {
+    if ( n == 0 ) {
+        return "no " + what + "s"
+    } else if ( n == 1 ) {
+        return "1 " + what
+    }
+
+    return fmt.Sprintf( "%d %ss" , n , what )
+}
@@ -2,37 +2,171 @@
 // John Simpson <jms1@jms1.net> 2022-08-27
 //
 // Main program - parses the command line and calls the appropriate read/write
-// functions based on what the user is asking for.
+// functions from the dx7 package based on what the user is asking for.
 
 package main
 
 import (
+    "errors"
     "flag"
     "fmt"
+    "io/fs"
+    "io/ioutil"
     "os"
     "regexp"
     "strings"
+
+    "jms1.net/volca-convert/dx7"
+)
+
+///////////////////////////////////////////////////////////////////////////////
+//
+// Exit codes. Each class of failure has its own code, so that scripts
+// can tell them apart.
+
+const (
+    EXIT_OK         = 0     // success
+    EXIT_USAGE      = 1     // bad command line
+    EXIT_IO         = 2     // unable to open/read/write a file
+    EXIT_PARSE      = 3     // input file contents not valid
+    EXIT_ENCODE     = 4     // voices can't be written in the output format
+    EXIT_OTHER      = 5     // anything else
+    EXIT_LINT       = 6     // 'lint' found problems
 )
 
 ///////////////////////////////////////////////////////////////////////////////
 //
 // usage
+//
+// The list of file types comes from the codecs registered in the dx7
+// package, so new formats show up here automatically.
 
-const usage_text = `volca-convert [options] INFILE [OUTFILE]
+const usage_head = `volca-convert [options] INFILE [OUTFILE]
+volca-convert [options] INFILE INFILE... OUTFILE
+volca-convert lint [options] INFILE
+volca-convert split [options] INFILE [TEMPLATE]
 
 Convert a Volca FM/FM2 (or DX7) "patch" file (a set of FM synthesis parameters
 which configure what kind of sound is made) from one format to another.
 
-Input file types: SYX, NONE, JSON, CSV
+If more than one INFILE is given, the voices from every file are combined (in
+the order the files are listed) and written to OUTFILE. The files don't need
+to be the same type. Any INFILE can be followed by a list of voice numbers to
+use only those voices, i.e. 'bank.syx:1-8' or 'more.json:3,7-12,30-'.
 
-Output file types: TEXT, CSV, JSON, SYX
+Use '-' as INFILE to read from STDIN. If no OUTFILE is given (or if it's '-'),
+the output is written to STDOUT.
 
--i ___  Specify the type of INFILE. This is needed if INFILE doesn't end
-        with '.json', '.syx', or '.csv'.
+SYX files can hold 1 or 32 voices. If there are between 2 and 31 voices, the
+bank is filled up to 32 with INIT VOICE (see --pad-voice). If there are more
+than 32 voices, OUTFILE must be a template containing a number, such as
+'out-%%02d.syx', and the voices will be split into out-01.syx, out-02.syx, and
+so on, with 32 voices in each file.
+
+SVG files have graphs of each voice's envelopes (as played on middle C) and
+keyboard level scaling. To write one SVG file per voice instead of one for
+the whole bank, use 'volca-convert split' with a TEMPLATE like
+'%%02d-{name}.svg'.
+
+Input file types: %s
+
+Output file types: %s
+
+File types:
+%s
+-i ___  Specify the type of INFILE. If this isn't used, the type is detected
+        from the filename (%s). If that doesn't work,
+        it is detected from the contents of the file. RAW files are only
+        detected this way if their name ends with .bin or .rom.
+
+-o ___  Specify the type of OUTFILE. This may be needed if the type can't be
+        detected from the filename (%s).
+        If the program can't tell what kind of file to write, it will
+        write TEXT by default.
+`
 
--o ___  Specify the type of OUTFILE. This may needed if OUTFILE doesn't end
-        with '.json', '.syx', or '.csv'. If the program can't tell what kind
-        of file to write, it will write TEXT by default.
+const usage_tail = `
+-v      Verbose. Show extra information, such as what kind of input file
+        was detected.
+
+--lenient
+        Show warnings instead of stopping for problems which still leave
+        usable voices, and convert the voices anyway:
+        - SYX   bad checksum or "end of message" marker, or the file was
+                cut off before all of its voices.
+        - JSON  missing or unknown parameters (missing ones are set to
+                their INIT VOICE values), or values outside of a
+                parameter's range (these are set to the closest valid
+                value).
+        - CSV   missing columns, or cells which aren't valid values (these
+                are set to their INIT VOICE values, or the closest valid
+                value).
+
+--fix-checksum
+        Don't convert anything. Instead, correct the checksum and "end of
+        message" marker of every voice dump in the SYX file INFILE, and
+        write the result to OUTFILE (or back to INFILE, if no OUTFILE is
+        given). Anything else in the file is left alone.
+
+-c ___  MIDI channel (1-16) to put in the header when writing SYX files
+        ('--channel' also works). A DX7 set to receive on a different
+        channel will ignore the dump. By default this is the same channel
+        as the input file (if it was a SYX file), or channel 1.
+
+--normalize
+        Before writing the output file, fix any values which are outside of
+        the range the DX7 allows (by using the closest valid value), set
+        the unused bits in the 32-voice SYX format to zero, and make every
+        voice name exactly 10 characters long, using only characters the
+        Volca can show (lower case letters become upper case, characters
+        such as "\" and "~" become "/" and "-", and anything else becomes
+        a space). Every change is listed on STDERR, and afterwards
+        'volca-convert lint' won't find any problems. (JSON files with
+        out-of-range values also need --lenient.)
+
+--voices ___
+        Only write some of the voices, i.e. '--voices 3,7-12' or '25-'.
+        The numbers are the voice's position after every INFILE has been
+        read.
+
+--name ___
+        Only write voices whose names match a pattern, such as 'BRASS*'. A
+        '*' matches any number of characters, and '?' matches any one
+        character. Upper/lower case doesn't matter.
+
+--name-regex ___
+        Same as --name, but the pattern is a regular expression, which can
+        match any part of the name, i.e. 'BASS|BS[0-9]'.
+
+--sort ___
+        Sort the voices by name, or by the value of any parameter, i.e.
+        '--sort ALGO' or '--sort OP1.OLVL'. Use '-' in front of the name
+        (i.e. '--sort=-NAME') to put the largest first. Voices which are
+        the same stay in the same order.
+
+--move ___
+        Move a voice to a different position, i.e. '--move 12:1' makes voice
+        12 the first voice, and moves the old 1-11 down to 2-12.
+
+--swap ___
+        Swap two voices, i.e. '--swap 3,4'.
+
+--reverse
+        Reverse the order of the voices.
+
+        --sort, --move, --swap, and --reverse can be used more than once, and
+        are done in the order they appear on the command line, after
+        --voices and --name. The voice numbers they use are the positions
+        after any earlier steps.
+
+--pad-voice ___
+        Use the first voice from this file (instead of INIT VOICE) to fill
+        up 32-voice banks which don't have enough voices.
+
+--force
+        Write binary output (SYX) to STDOUT even if it's a terminal.
+        Normally the program refuses to do this, since it can mess up the
+        terminal.
 
 -s      Generate "simple" output. The exact meaning of this depends on what
         kind of output file is being created.
@@ -42,40 +176,478 @@ Output file types: TEXT, CSV, JSON, SYX
                 for humans to read/edit.
         - SYX   no affect.
 
+--symbolic
+        When writing JSON or CSV, use names instead of numbers for the
+        parameters which have them: curves (LSLC/LSRC) as -LIN, -EXP, +EXP,
+        or +LIN, LFO waves (LFOW) as TRIANGLE, SAW DOWN, SAW UP, SQUARE, SINE,
+        or S/HOLD, OSCM as RATIO or FIXED, OKS/LFOK as ON or OFF, LSBP/TRSP
+        as note names (C3 is middle C), and DETU as -7 to +7 (always with a
+        sign). JSON and CSV files can use either form when they're read.
+        A DETU with a sign is always -7 to +7. In a file which uses names
+        for any other parameter, a DETU number without a sign is also -7 to
+        +7 in JSON files, and an error in CSV files, since "3" could mean
+        either +3 or the number 3 (which is -4).
+
+--roles
+        When writing JSON or CSV, show whether each operator is a CARRIER
+        (an operator you actually hear) or a MODULATOR, which depends on the
+        algorithm. JSON files get a "ROLE" in each operator, and CSV files
+        get six more columns at the end. These are only for humans, and are
+        ignored when the file is read. (TEXT files always show them.)
+
+--no-diagram
+        When writing TEXT, don't draw each voice's algorithm. Normally the
+        operators are drawn with the carriers (the operators you actually
+        hear) at the bottom, each modulator above the operator(s) it
+        modulates, and '*' marking the operator(s) in the feedback loop.
+
+--verbose-values
+        When writing TEXT, add a line after each group of parameters which
+        shows what the values mean: the algorithm number (1-32), each
+        operator's frequency ratio or fixed frequency in Hz, detune as -7 to
+        +7, break point and transpose as note names (C3 is middle C), curve
+        and LFO wave names, and whether each sync option is ON or OFF.
+
 You can use '-i none' to not read any input file, which is useful if you need
 to create a CSV file with just the headers. If you do this, no input filename
 is needed, and the first filename on the command line will be used as the
 output filename.
 
+Exit codes:
+    0   success
+    1   invalid command line
+    2   unable to open, read, or write a file
+    3   input file contents are not valid
+    4   voices cannot be written in the requested output format
+    5   other errors
+    6   'lint' found problems with the voices
+
+Run 'volca-convert lint -h' or 'volca-convert split -h' for the options used
+with 'lint' and 'split'.
+
 Source: https://github.com/kg4zow/volca-convert
 
 `
 
+func usage_text() string {
+    var in_types    []string
+    var out_types   []string
+    var in_exts     []string
+    var out_exts    []string
+    var table       string
+
+    for _ , c := range dx7.Codecs() {
+        name := c.Names()[0]
+        exts := c.Extensions()
+
+        var rw []string
+
+        if ( c.CanDecode() ) {
+            in_types = append( in_types , name )
+            in_exts  = append( in_exts , exts... )
+            rw       = append( rw , "read" )
+        }
+
+        if ( c.CanEncode() ) {
+            out_types = append( out_types , name )
+            out_exts  = append( out_exts , exts... )
+            rw        = append( rw , "write" )
+        }
+
+        table += fmt.Sprintf( "    %-6s %-7s %-11s %s\n" , name ,
+            strings.Join( exts , " " ) , strings.Join( rw , "/" ) ,
+            c.Description() )
+    }
+
+    in_types = append( in_types , "NONE" )
+
+    return fmt.Sprintf( usage_head ,
+        strings.Join( in_types , ", " ) , strings.Join( out_types , ", " ) ,
+        table , strings.Join( in_exts , " " ) ,
+        strings.Join( out_exts , " " ) ) + usage_tail
+}
+
+////////////////////////////////////////
+// Usage message for the command being run. Subcommands change this, so
+// that errors found by code they share with the main command (such as
+// reading INFILE) show the subcommand's usage.
+
+var command_usage = usage_text
+
 func usage() {
-    fmt.Print( usage_text )
-    os.Exit( 0 )
+    fmt.Print( command_usage() )
+    os.Exit( EXIT_OK )
 }
 
 func usage_msg( msg string ) {
-    fmt.Print( usage_text )
+    fmt.Print( command_usage() )
     fmt.Println( msg )
-    os.Exit( 1 )
+    os.Exit( EXIT_USAGE )
 }
 
-func fail( msg string ) {
-    fmt.Println( msg )
-    os.Exit( 1 )
+///////////////////////////////////////////////////////////////////////////////
+//
+// Report an error returned by the dx7 package, and exit with the code
+// matching the class of error.
+
+func fail( err error ) {
+    var pe      *dx7.ParseError
+    var ee      *dx7.EncodeError
+    var fe      *fs.PathError
+
+    show( "ERROR" , err )
+
+    if ( errors.As( err , &pe ) || dx7.Recoverable( err ) ) {
+        os.Exit( EXIT_PARSE )
+    } else if ( errors.As( err , &ee ) ) {
+        os.Exit( EXIT_ENCODE )
+    } else if ( errors.As( err , &fe ) ) {
+        os.Exit( EXIT_IO )
+    }
+
+    os.Exit( EXIT_OTHER )
+}
+
+////////////////////////////////////////
+// Print an error (or every error in a list) to STDERR
+
+func show( prefix string , err error ) {
+    if list , ok := err.( dx7.ErrorList ) ; ok {
+        for _ , e := range list {
+            fmt.Fprintf( os.Stderr , "%s: %s\n" , prefix , e )
+        }
+        return
+    }
+
+    fmt.Fprintf( os.Stderr , "%s: %s\n" , prefix , err )
+}
+
+////////////////////////////////////////
+// Return true if a file is a terminal
+
+func is_terminal( f *os.File ) bool {
+    fi , err := f.Stat()
+    if ( err != nil ) {
+        return false
+    }
+
+    return ( fi.Mode() & os.ModeCharDevice ) != 0
+}
+
+///////////////////////////////////////////////////////////////////////////////
+//
+// Correct the checksum and end marker of a SYX file. Either filename can
+// be "-" for STDIN/STDOUT.
+
+func fix_checksum( infile string , outfile string , verbose bool ) {
+    var data    []byte
+    var err     error
+
+    if ( infile == "-" ) {
+        data , err = ioutil.ReadAll( os.Stdin )
+    } else {
+        data , err = os.ReadFile( infile )
+    }
+    if ( err != nil ) {
+        fail( err )
+    }
+
+    fixed , changed , err := dx7.FixChecksum( data )
+    if ( err != nil ) {
+        var pe *dx7.ParseError
+        if ( errors.As( err , &pe ) ) {
+            if ( infile != "-" ) {
+                pe.File = infile
+            }
+        }
+        fail( err )
+    }
+
+    if ( verbose ) {
+        if ( changed ) {
+            fmt.Fprintf( os.Stderr , "INFO: \"%s\" checksum corrected\n" , infile )
+        } else {
+            fmt.Fprintf( os.Stderr , "INFO: \"%s\" checksum was already correct\n" , infile )
+        }
+    }
+
+    if ( outfile == "-" ) {
+        _ , err = os.Stdout.Write( fixed )
+    } else if ( !changed && ( outfile == infile ) ) {
+        return
+    } else {
+        err = os.WriteFile( outfile , fixed , 0644 )
+    }
+    if ( err != nil ) {
+        fail( err )
+    }
+}
+
+///////////////////////////////////////////////////////////////////////////////
+//
+// Figure out the input file type.
+// - If no '-i' option was used, the program will try to detect it,
+//   based on the filename.
+// - If the filename doesn't match one of the recognized patterns,
+//   look at the contents of the file.
+// - If that doesn't work either, fail.
+
+func input_codec( infile string , itype string , verbose bool ) dx7.Codec {
+    if ( itype != "" ) {
+        c := dx7.CodecByName( itype )
+        if ( ( c == nil ) || !c.CanDecode() ) {
+            usage_msg( fmt.Sprintf( "ERROR: unable to read '%s' files" , itype ) )
+        }
+        return c
+    }
+
+    c   := dx7.CodecForFile( infile )
+    how := "filename"
+
+    if ( ( c == nil ) || !c.CanDecode() ) {
+        var err error
+
+        c , err = dx7.DetectFile( infile )
+        how     = "contents"
+        if ( err != nil ) {
+            fail( err )
+        }
+    }
+
+    if ( c == nil ) {
+        usage_msg( "ERROR: unable to tell what kind of input file to read" )
+    }
+
+    if ( verbose ) {
+        fmt.Fprintf( os.Stderr , "INFO: \"%s\" detected as %s (from %s)\n" ,
+            infile , c.Names()[0] , how )
+    }
+
+    return c
+}
+
+///////////////////////////////////////////////////////////////////////////////
+//
+// Read the voices from one INFILE argument. If the argument ends with a list
+// of voice numbers (i.e. "bank.syx:1-8"), only those voices are returned.
+
+func read_input( arg string , itype string , verbose bool , lenient bool ) dx7.Bank {
+    infile , spec := input_spec( arg )
+
+    bank , err := dx7.ReadFile( infile , input_codec( infile , itype , verbose ) )
+    if ( err != nil ) {
+        if ( !lenient || !dx7.Recoverable( err ) ) {
+            fail( err )
+        }
+
+        show( "WARNING" , err )
+    }
+
+    if ( spec != "" ) {
+        bank , err = dx7.SelectVoices( bank , spec )
+        if ( err != nil ) {
+            usage_msg( fmt.Sprintf( "ERROR: \"%s\": %s" , infile , err ) )
+        }
+    }
+
+    if ( verbose ) {
+        fmt.Fprintf( os.Stderr , "INFO: read %s from \"%s\"\n" ,
+            plural( len( bank.Voices ) , "voice" ) , infile )
+    }
+
+    return bank
+}
+
+////////////////////////////////////////
+// Split an INFILE argument into the filename and the list of voices. A
+// file which actually exists with the full name is always used as-is.
+
+func input_spec( arg string ) ( string , string ) {
+    n := strings.LastIndex( arg , ":" )
+    if ( n < 0 ) {
+        return arg , ""
+    }
+
+    if _ , err := os.Stat( arg ) ; err == nil {
+        return arg , ""
+    }
+
+    spec := arg[(n+1):]
+    if ( ( spec == "" ) || ( strings.Trim( spec , "0123456789,-" ) != "" ) ) {
+        return arg , ""
+    }
+
+    return arg[:n] , spec
+}
+
+///////////////////////////////////////////////////////////////////////////////
+//
+// Apply the --voices, --name, and --name-regex options, in that order.
+
+func select_voices( bank dx7.Bank , voices string , name_glob string ,
+    name_regex string ) dx7.Bank {
+
+    var err error
+
+    if ( voices != "" ) {
+        bank , err = dx7.SelectVoices( bank , voices )
+        if ( err != nil ) {
+            usage_msg( fmt.Sprintf( "ERROR: --voices: %s" , err ) )
+        }
+    }
+
+    if ( name_glob != "" ) {
+        bank , err = dx7.SelectNames( bank , name_glob , false )
+        if ( err != nil ) {
+            usage_msg( fmt.Sprintf( "ERROR: --name: %s" , err ) )
+        }
+    }
+
+    if ( name_regex != "" ) {
+        bank , err = dx7.SelectNames( bank , name_regex , true )
+        if ( err != nil ) {
+            usage_msg( fmt.Sprintf( "ERROR: --name-regex: %s" , err ) )
+        }
+    }
+
+    if ( len( bank.Voices ) < 1 ) {
+        fmt.Fprintln( os.Stderr , "ERROR: none of the voices were selected" )
+        os.Exit( EXIT_OTHER )
+    }
+
+    return bank
+}
+
+///////////////////////////////////////////////////////////////////////////////
+//
+// Read the voice used to fill up banks. This is the first voice in a file
+// of any type.
+
+func read_pad_voice( filename string , verbose bool ) *dx7.Voice {
+    bank , err := dx7.ReadFile( filename , input_codec( filename , "" , verbose ) )
+    if ( err != nil ) {
+        fail( err )
+    }
+
+    if ( len( bank.Voices ) < 1 ) {
+        usage_msg( fmt.Sprintf( "ERROR: \"%s\" doesn't contain any voices" , filename ) )
+    }
+
+    return &bank.Voices[0]
+}
+
+///////////////////////////////////////////////////////////////////////////////
+//
+// Return true if OUTFILE should be used as a template for write_split().
+// This is only needed for SYX files with more than 32 voices, and only if
+// OUTFILE contains one "%d" (or "%02d", etc.). Anything else is a normal
+// filename which happens to contain "%", i.e. "50%.json".
+
+var template_verb = regexp.MustCompile( `%0?[0-9]*d` )
+
+func is_template( outfile string , c dx7.Codec , bank dx7.Bank ) bool {
+    if ( ( c != dx7.SYXCodec ) || ( len( bank.Voices ) <= 32 ) ) {
+        return false
+    }
+
+    name := strings.ReplaceAll( outfile , "%%" , "" )
+    return ( strings.Count( name , "%" ) == 1 ) && template_verb.MatchString( name )
+}
+
+///////////////////////////////////////////////////////////////////////////////
+//
+// Split the voices into banks of 32, and write each bank to a file whose
+// name comes from a template, i.e. "out-%02d.syx" = out-01.syx, out-02.syx.
+
+func write_split( template string , c dx7.Codec , bank dx7.Bank ,
+    opt dx7.Options , verbose bool ) {
+
+    var names []string
+
+    parts := dx7.SplitBank( bank , 32 )
+
+    ////////////////////////////////////////
+    // Make sure the template gives a different name for each file before
+    // writing anything.
+
+    for n := range parts {
+        name := fmt.Sprintf( template , n + 1 )
+        if ( strings.Contains( name , "%!" ) ) {
+            usage_msg( fmt.Sprintf( "ERROR: invalid OUTFILE template '%s'" , template ) )
+        }
+
+        for _ , x := range names {
+            if ( x == name ) {
+                usage_msg( fmt.Sprintf( "ERROR: OUTFILE template '%s' doesn't contain a number" ,
+                    template ) )
+            }
+        }
+
+        names = append( names , name )
+    }
+
+    ////////////////////////////////////////
+    // Write the files
+
+    for n , part := range parts {
+        err := dx7.WriteFile( names[n] , c , part , opt )
+        if ( err != nil ) {
+            fail( err )
+        }
+
+        if ( verbose ) {
+            fmt.Fprintf( os.Stderr , "INFO: wrote %d voices to \"%s\"\n" ,
+                len( part.Voices ) , names[n] )
+        }
+    }
+}
+
+////////////////////////////////////////
+// Filename extension for a codec's files, for messages
+
+func out_ext( c dx7.Codec ) string {
+    if ( len( c.Extensions() ) > 0 ) {
+        return c.Extensions()[0]
+    }
+
+    return ""
 }
 
 ///////////////////////////////////////////////////////////////////////////////
 
 func main() {
-    var infile      string
+    var infiles     []string
     var outfile     string
+    var bank        dx7.Bank
+    var err         error
+
+    var out_codec   dx7.Codec
+    var in_none     bool
+    var verbose     bool
+    var lenient     bool
+    var fix_cs      bool
+    var force       bool
+    var normalize   bool
+    var pad_file    string
+    var voices      string
+    var name_glob   string
+    var name_regex  string
+    var order       order_list
+    var opt         dx7.Options
 
-    var in_type     FileType
-    var out_type    FileType
-    var out_simple  bool
+    ////////////////////////////////////////////////////////////
+    // Subcommands have their own options
+
+    if ( len( os.Args ) > 1 ) {
+        switch ( os.Args[1] ) {
+            case "lint":
+                lint_main( os.Args[2:] )
+                return
+            case "split":
+                split_main( os.Args[2:] )
+                return
+        }
+    }
 
     ////////////////////////////////////////////////////////////
     // Set up and parse command line options
@@ -83,106 +655,163 @@ func main() {
     var itype string
     var otype string
 
-    flag.StringVar( &itype    , "i" , ""    , "input type" )
-    flag.StringVar( &otype    , "o" , ""    , "output type" )
-    flag.BoolVar( &out_simple , "s" , false , "simple output" )
+    flag.StringVar( &itype      , "i" , ""    , "input type" )
+    flag.StringVar( &otype      , "o" , ""    , "output type" )
+    flag.BoolVar( &opt.Simple   , "s" , false , "simple output" )
+    flag.IntVar( &opt.Channel   , "c" , 0 , "MIDI channel" )
+    flag.IntVar( &opt.Channel   , "channel" , 0 , "MIDI channel" )
+    flag.BoolVar( &verbose      , "v" , false , "verbose" )
+    flag.BoolVar( &opt.VerboseValues , "verbose-values" , false , "explain values in TEXT" )
+    flag.BoolVar( &opt.Symbolic , "symbolic" , false , "use names for values in JSON/CSV" )
+    flag.BoolVar( &opt.NoDiagram , "no-diagram" , false , "don't draw algorithms in TEXT" )
+    flag.BoolVar( &opt.Roles , "roles" , false , "show operator roles in JSON/CSV" )
+    flag.BoolVar( &lenient      , "lenient" , false , "warn about bad checksums" )
+    flag.BoolVar( &fix_cs       , "fix-checksum" , false , "repair SYX checksum" )
+    flag.BoolVar( &force        , "force" , false , "write binary to terminal" )
+    flag.BoolVar( &normalize    , "normalize" , false , "fix out-of-range values" )
+    flag.StringVar( &pad_file   , "pad-voice" , "" , "voice used to pad banks" )
+    flag.StringVar( &voices     , "voices" , "" , "voices to write" )
+    flag.StringVar( &name_glob  , "name" , "" , "names to write (glob)" )
+    flag.StringVar( &name_regex , "name-regex" , "" , "names to write (regex)" )
+    order_flags( flag.CommandLine , &order )
 
     flag.Usage = usage
     flag.Parse()
 
     ////////////////////////////////////////
-    // Get input and output filenames
+    // Get input and output filenames. If there's more than one filename,
+    // the last one is the output file.
 
-    infile  = flag.Arg( 0 )
-    outfile = flag.Arg( 1 )
+    infiles = flag.Args()
+    if ( len( infiles ) > 1 ) {
+        outfile = infiles[ len( infiles ) - 1 ]
+        infiles = infiles[ :len( infiles ) - 1 ]
+    }
 
-    ////////////////////////////////////////////////////////////
-    // Figure out the input and output file types
+    if ( ( opt.Channel < 0 ) || ( opt.Channel > 16 ) ) {
+        usage_msg( fmt.Sprintf( "ERROR: MIDI channel must be 1-16, not %d" , opt.Channel ) )
+    }
+
+    ////////////////////////////////////////
+    // Repairing a checksum doesn't involve any conversion
 
-    is_json := regexp.MustCompile( "(?i)\\.json$" )
-    is_syx  := regexp.MustCompile( "(?i)\\.syx$"  )
-    is_csv  := regexp.MustCompile( "(?i)\\.csv$"  )
+    if ( fix_cs ) {
+        if ( len( infiles ) != 1 ) {
+            usage_msg( "ERROR: --fix-checksum needs one input file" )
+        }
+
+        infile := infiles[0]
+
+        if ( outfile == "" ) {
+            outfile = infile
+        }
+
+        if ( ( outfile == "-" ) && !force && is_terminal( os.Stdout ) ) {
+            usage_msg( "ERROR: refusing to write SYX data to a terminal (use --force)" )
+        }
+
+        fix_checksum( infile , outfile , verbose )
+        return
+    }
 
     ////////////////////////////////////////
-    // Figure out the input file type.
-    // - If no '-i' option was used, the program will try to detect it,
-    //   based on the filename.
-    // - If the filename doesn't match one of the recognized patterns, fail.
+    // '-i none' means there is no input file, so the first filename is the
+    // output file.
 
     if ( strings.EqualFold( itype , "NONE" ) ) {
-        in_type = NONE
-        infile  = ""
+        in_none = true
+        infiles = nil
         outfile = flag.Arg(0)
-    } else if ( strings.EqualFold( itype , "SYX" ) ) {
-        in_type = SYX
-    } else if ( strings.EqualFold( itype , "JSON" ) ) {
-        in_type = JSON
-    } else if ( strings.EqualFold( itype , "CSV" ) ) {
-        in_type = CSV
-    } else if ( infile == "" ) {
+    } else if ( len( infiles ) < 1 ) {
         usage()
-    } else if ( is_json.MatchString( infile ) ) {
-        in_type = JSON
-    } else if ( is_syx.MatchString( infile ) ) {
-        in_type = SYX
-    } else if ( is_csv.MatchString( infile ) ) {
-        in_type = CSV
-    } else {
-        usage_msg( "ERROR: unable to tell what kind of input file to read" )
     }
 
     ////////////////////////////////////////
     // Figure out the output file type.
-    // - If we can't tell what kind of file to write, fail.
-    // - SYX files are binary, do not write to STDOUT.
-
-    if ( strings.EqualFold( otype , "TEXT" ) ) {
-        out_type = TEXT
-    } else if ( strings.EqualFold( otype , "TXT" ) ) {
-        out_type = TEXT
-    } else if ( strings.EqualFold( otype , "CSV" ) ) {
-        out_type = CSV
-    } else if ( strings.EqualFold( otype , "JSON" ) ) {
-        out_type = JSON
-    } else if ( strings.EqualFold( otype , "SYX" ) ) {
-        out_type = SYX
-    } else if ( is_json.MatchString( outfile ) ) {
-        out_type = JSON
-    } else if ( is_syx.MatchString( outfile ) ) {
-        out_type = SYX
-    } else if ( is_csv.MatchString( outfile ) ) {
-        out_type = CSV
+    // - If we can't tell what kind of file to write, write TEXT.
+
+    if ( otype != "" ) {
+        out_codec = dx7.CodecByName( otype )
+        if ( ( out_codec == nil ) || !out_codec.CanEncode() ) {
+            usage_msg( fmt.Sprintf( "ERROR: unable to write '%s' files" , otype ) )
+        }
     } else {
-        out_type = TEXT
+        out_codec = dx7.CodecForFile( outfile )
+        if ( ( out_codec == nil ) || !out_codec.CanEncode() ) {
+            out_codec = dx7.TextCodec
+        }
+    }
+
+    ////////////////////////////////////////
+    // Binary data written to a terminal just makes a mess
+
+    if ( ( outfile == "" ) || ( outfile == "-" ) ) {
+        if ( out_codec.Binary() && !force && is_terminal( os.Stdout ) ) {
+            usage_msg( fmt.Sprintf( "ERROR: refusing to write %s data to a terminal (use --force)" ,
+                out_codec.Names()[0] ) )
+        }
     }
 
     ////////////////////////////////////////////////////////////
-    // Read/parse input file into memory
-
-    if ( in_type == NONE ) {
-        // do nothing
-    } else if ( in_type == SYX ) {
-        read_syx( infile )
-    } else if ( in_type == JSON ) {
-        read_json( infile )
-    } else if ( in_type == CSV ) {
-        read_csv( infile )
-    } else {
-        usage_msg( "ERROR: requested reader not recognized (bug)" )
+    // Read/parse input files into memory. The device number comes from
+    // the first file.
+
+    if ( !in_none ) {
+        for n , arg := range infiles {
+            part := read_input( arg , itype , verbose , lenient )
+            if ( n == 0 ) {
+                bank.Device = part.Device
+            }
+            bank.Voices = append( bank.Voices , part.Voices... )
+        }
     }
 
     ////////////////////////////////////////////////////////////
-    // Write memory to output file
-
-    if ( out_type == TEXT ) {
-        write_text( outfile , !out_simple )
-    } else if ( out_type == CSV ) {
-        write_csv( outfile , !out_simple )
-    } else if ( out_type == JSON ) {
-        write_json( outfile , !out_simple )
-    } else if ( out_type == SYX ) {
-        write_syx( outfile )
-    } else {
-        usage_msg( "ERROR: requested writer not recognized (bug)" )
+    // Keep only the voices which were asked for
+
+    if ( ( voices != "" ) || ( name_glob != "" ) || ( name_regex != "" ) ) {
+        bank = select_voices( bank , voices , name_glob , name_regex )
+    }
+
+    ////////////////////////////////////////////////////////////
+    // Rearrange the voices
+
+    err = order.apply( &bank )
+    if ( err != nil ) {
+        usage_msg( fmt.Sprintf( "ERROR: %s" , err ) )
+    }
+
+    ////////////////////////////////////////////////////////////
+    // Read the voice used to fill up banks, if one was given
+
+    if ( pad_file != "" ) {
+        opt.Pad = read_pad_voice( pad_file , verbose )
+    }
+
+    ////////////////////////////////////////////////////////////
+    // Fix any values the DX7 can't use
+
+    if ( normalize ) {
+        for _ , c := range dx7.NormalizeBank( &bank ) {
+            fmt.Fprintf( os.Stderr , "CHANGED: %s\n" , c )
+        }
+    }
+
+    ////////////////////////////////////////////////////////////
+    // Write memory to output file(s)
+
+    if ( is_template( outfile , out_codec , bank ) ) {
+        write_split( outfile , out_codec , bank , opt , verbose )
+        return
+    }
+
+    err = dx7.WriteFile( outfile , out_codec , bank , opt )
+    if ( err != nil ) {
+        var ee *dx7.EncodeError
+        if ( errors.As( err , &ee ) && ( len( bank.Voices ) > 32 ) ) {
+            fmt.Fprintf( os.Stderr , "INFO: use an OUTFILE template like 'out-%%02d%s' " +
+                "to split the voices into several files\n" , out_ext( out_codec ) )
+        }
+        fail( err )
     }
 }
@@ -0,0 +1,41 @@
+// volca-convert - main_test.go
+// John Simpson <jms1@jms1.net> 2022-10-02
+
+package main
+
+import (
+    "testing"
+
+    "jms1.net/volca-convert/dx7"
+)
+
+func TestIsTemplate( t *testing.T ) {
+    small := dx7.Bank{ Voices: make( []dx7.Voice , 3 ) }
+    large := dx7.Bank{ Voices: make( []dx7.Voice , 40 ) }
+
+    tests := []struct {
+        outfile string
+        c       dx7.Codec
+        bank    dx7.Bank
+        want    bool
+    }{
+        { "out-%02d.syx"    , dx7.SYXCodec  , large , true  } ,
+        { "out-%d.syx"      , dx7.SYXCodec  , large , true  } ,
+        { "100%%-%d.syx"    , dx7.SYXCodec  , large , true  } ,
+        { "mix%.syx"        , dx7.SYXCodec  , small , false } ,
+        { "out-%02d.syx"    , dx7.SYXCodec  , small , false } ,
+        { "50%.json"        , dx7.JSONCodec , large , false } ,
+        { "out-%02d.json"   , dx7.JSONCodec , large , false } ,
+        { "mix%.syx"        , dx7.SYXCodec  , large , false } ,
+        { "%s.syx"          , dx7.SYXCodec  , large , false } ,
+        { "%d-%d.syx"       , dx7.SYXCodec  , large , false } ,
+    }
+
+    for _ , test := range tests {
+        got := is_template( test.outfile , test.c , test.bank )
+        if ( got != test.want ) {
+            t.Errorf( "%s (%d voices): got %v, expected %v" ,
+                test.outfile , len( test.bank.Voices ) , got , test.want )
+        }
+    }
+}
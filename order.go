@@ -0,0 +1,163 @@
+// volca-convert - order.go
+// John Simpson <jms1@jms1.net> 2022-10-03
+//
+// The --sort, --move, --swap, and --reverse options. These are applied in
+// the order they appear on the command line, so each one is a flag.Value
+// which adds a step to the same list.
+
+package main
+
+import (
+    "flag"
+    "fmt"
+    "strconv"
+    "strings"
+
+    "jms1.net/volca-convert/dx7"
+)
+
+///////////////////////////////////////////////////////////////////////////////
+//
+// One step, and the list of steps
+
+type order_step struct {
+    what    string
+    run     func( bank *dx7.Bank ) error
+}
+
+type order_list []order_step
+
+////////////////////////////////////////
+// flag.Value for one of the options. "parse" checks the option's value and
+// returns the function which does the work.
+
+type order_flag struct {
+    list    *order_list
+    name    string
+    is_bool bool
+    parse   func( value string ) ( func( bank *dx7.Bank ) error , error )
+}
+
+func (f order_flag) String() string     { return "" }
+func (f order_flag) IsBoolFlag() bool   { return f.is_bool }
+
+func (f order_flag) Set( value string ) error {
+    run , err := f.parse( value )
+    if ( err != nil ) {
+        return err
+    }
+
+    what := "--" + f.name
+    if ( !f.is_bool ) {
+        what += " " + value
+    }
+
+    *f.list = append( *f.list , order_step{ what , run } )
+    return nil
+}
+
+///////////////////////////////////////////////////////////////////////////////
+//
+// Add the options to a FlagSet
+
+func order_flags( flags *flag.FlagSet , list *order_list ) {
+    flags.Var( order_flag{ list , "sort" , false , parse_sort } ,
+        "sort" , "sort voices" )
+    flags.Var( order_flag{ list , "move" , false , parse_move } ,
+        "move" , "move a voice" )
+    flags.Var( order_flag{ list , "swap" , false , parse_swap } ,
+        "swap" , "swap two voices" )
+    flags.Var( order_flag{ list , "reverse" , true , parse_reverse } ,
+        "reverse" , "reverse the order of the voices" )
+}
+
+////////////////////////////////////////
+// Run every step, in order
+
+func ( list order_list ) apply( bank *dx7.Bank ) error {
+    for _ , step := range list {
+        if err := step.run( bank ) ; err != nil {
+            return fmt.Errorf( "%s: %s" , step.what , err )
+        }
+    }
+
+    return nil
+}
+
+///////////////////////////////////////////////////////////////////////////////
+//
+// Parse each option's value
+
+func parse_sort( value string ) ( func( bank *dx7.Bank ) error , error ) {
+    key := strings.ToUpper( strings.TrimPrefix( value , "-" ) )
+    if ( key != "NAME" ) {
+        p := dx7.ParamByName( key )
+        if ( ( p == nil ) || p.Unused ) {
+            return nil , fmt.Errorf( "unknown sort key \"%s\"" , value )
+        }
+    }
+
+    return func( bank *dx7.Bank ) error {
+        return dx7.SortBank( bank , value )
+    } , nil
+}
+
+func parse_move( value string ) ( func( bank *dx7.Bank ) error , error ) {
+    from , to , err := parse_pair( value , ":" )
+    if ( err != nil ) {
+        return nil , err
+    }
+
+    return func( bank *dx7.Bank ) error {
+        return dx7.MoveVoice( bank , from , to )
+    } , nil
+}
+
+func parse_swap( value string ) ( func( bank *dx7.Bank ) error , error ) {
+    a , b , err := parse_pair( value , "," )
+    if ( err != nil ) {
+        return nil , err
+    }
+
+    return func( bank *dx7.Bank ) error {
+        return dx7.SwapVoices( bank , a , b )
+    } , nil
+}
+
+func parse_reverse( value string ) ( func( bank *dx7.Bank ) error , error ) {
+    on , err := strconv.ParseBool( value )
+    if ( err != nil ) {
+        return nil , err
+    }
+
+    return func( bank *dx7.Bank ) error {
+        if ( on ) {
+            dx7.ReverseBank( bank )
+        }
+        return nil
+    } , nil
+}
+
+////////////////////////////////////////
+// Parse two voice numbers separated by "sep", i.e. "12:1"
+
+func parse_pair( value string , sep string ) ( int , int , error ) {
+    bad := fmt.Errorf( "expected two voice numbers, like '12%s1'" , sep )
+
+    x , y , ok := strings.Cut( value , sep )
+    if ( !ok ) {
+        return 0 , 0 , bad
+    }
+
+    a , err := strconv.Atoi( strings.TrimSpace( x ) )
+    if ( err != nil ) {
+        return 0 , 0 , bad
+    }
+
+    b , err := strconv.Atoi( strings.TrimSpace( y ) )
+    if ( err != nil ) {
+        return 0 , 0 , bad
+    }
+
+    return a , b , nil
+}
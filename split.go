@@ -0,0 +1,245 @@
+// volca-convert - split.go
+// John Simpson <jms1@jms1.net> 2022-10-01
+//
+// The "split" subcommand - write each voice in a file to its own file.
+
+package main
+
+import (
+    "flag"
+    "fmt"
+    "os"
+    "strings"
+
+    "jms1.net/volca-convert/dx7"
+)
+
+///////////////////////////////////////////////////////////////////////////////
+//
+// usage
+
+const split_usage = `volca-convert split [options] INFILE [TEMPLATE]
+
+Write each voice in INFILE to its own file. This is useful for synths (such as
+the original Volca FM firmware) or programs (such as Dexed) which load voices
+one at a time.
+
+INFILE can be followed by a list of voice numbers to only write those voices,
+i.e. 'bank.syx:1-8'.
+
+The filenames come from TEMPLATE, which may contain:
+
+    %%d      the voice number (%%02d = two digits, i.e. 01, 02, ...)
+    {name}  the voice's name, with spaces and punctuation changed to '_'
+
+The default TEMPLATE is '%s'. It may include a directory, which
+must already exist.
+
+If two voices would be written to the same filename, or if a file already
+exists (and --overwrite wasn't used), nothing is written.
+
+-i ___  Specify the type of INFILE, the same as when converting a file.
+
+-o ___  Specify the type of the output files. If this isn't used, the type
+        is detected from TEMPLATE, or SYX if that doesn't work.
+
+-c ___  MIDI channel (1-16) to put in the header of SYX files.
+
+-s      Generate "simple" output, the same as when converting a file.
+
+--lenient
+        Show warnings instead of stopping for problems which still leave
+        usable voices, the same as when converting a file.
+
+--overwrite
+        Replace files which already exist.
+
+-v      Verbose. Show each file as it's written.
+
+`
+
+const split_template = "%02d-{name}.syx"
+
+func split_usage_text() string {
+    return fmt.Sprintf( split_usage , split_template )
+}
+
+///////////////////////////////////////////////////////////////////////////////
+//
+// Make a voice name safe to use in a filename. Anything other than letters
+// and digits becomes '_', and spaces at the end are removed.
+
+func filename_safe( name string ) string {
+    name = strings.TrimRight( name , " " )
+    if ( name == "" ) {
+        return "UNNAMED"
+    }
+
+    return strings.Map( func( c rune ) rune {
+        if ( ( c >= 'A' && c <= 'Z' ) || ( c >= 'a' && c <= 'z' ) ||
+                ( c >= '0' && c <= '9' ) ) {
+            return c
+        }
+        return '_'
+    } , name )
+}
+
+////////////////////////////////////////
+// Build the filename for one voice. "num" starts at 1.
+
+func split_filename( template string , num int , v *dx7.Voice ) ( string , error ) {
+    name := strings.ReplaceAll( template , "{name}" , filename_safe( v.Name ) )
+
+    if ( strings.Contains( name , "%" ) ) {
+        name = fmt.Sprintf( name , num )
+        if ( strings.Contains( name , "%!" ) ) {
+            return "" , fmt.Errorf( "invalid TEMPLATE '%s'" , template )
+        }
+    }
+
+    return name , nil
+}
+
+////////////////////////////////////////
+// Figure out the filename for every voice in a bank. Returns the names,
+// plus a list of problems (two voices with the same filename, or files
+// which already exist if "overwrite" is false). If there are any problems,
+// nothing should be written.
+
+func split_names( template string , bank dx7.Bank , overwrite bool ) ( []string , []string , error ) {
+    var names       []string
+    var problems    []string
+
+    used := make( map[string]int )
+
+    for n := range bank.Voices {
+        name , err := split_filename( template , n + 1 , &bank.Voices[n] )
+        if ( err != nil ) {
+            return nil , nil , err
+        }
+
+        if prev , ok := used[name] ; ok {
+            problems = append( problems , fmt.Sprintf(
+                "voices %d and %d would both be written to \"%s\"" , prev , n + 1 , name ) )
+        } else if _ , err := os.Stat( name ) ; ( err == nil ) && !overwrite {
+            problems = append( problems , fmt.Sprintf(
+                "voice %d: \"%s\" already exists (use --overwrite)" , n + 1 , name ) )
+        }
+
+        used[name] = n + 1
+        names = append( names , name )
+    }
+
+    return names , problems , nil
+}
+
+////////////////////////////////////////
+// Write each voice in a bank to the matching filename from split_names()
+
+func split_write( bank dx7.Bank , names []string , c dx7.Codec , opt dx7.Options ,
+    verbose bool ) error {
+
+    for n := range bank.Voices {
+        part := dx7.Bank{
+            Voices  : bank.Voices[n:(n+1)] ,
+            Device  : bank.Device ,
+        }
+
+        err := dx7.WriteFile( names[n] , c , part , opt )
+        if ( err != nil ) {
+            return err
+        }
+
+        if ( verbose ) {
+            fmt.Fprintf( os.Stderr , "INFO: voice %d written to \"%s\"\n" , n + 1 , names[n] )
+        }
+    }
+
+    return nil
+}
+
+///////////////////////////////////////////////////////////////////////////////
+
+func split_main( args []string ) {
+    var itype       string
+    var otype       string
+    var verbose     bool
+    var lenient     bool
+    var overwrite   bool
+    var opt         dx7.Options
+
+    flags := flag.NewFlagSet( "split" , flag.ExitOnError )
+    flags.StringVar( &itype       , "i" , ""    , "input type" )
+    flags.StringVar( &otype       , "o" , ""    , "output type" )
+    flags.IntVar( &opt.Channel    , "c" , 0     , "MIDI channel" )
+    flags.BoolVar( &opt.Simple    , "s" , false , "simple output" )
+    flags.BoolVar( &lenient       , "lenient" , false , "warn about recoverable problems" )
+    flags.BoolVar( &overwrite     , "overwrite" , false , "replace existing files" )
+    flags.BoolVar( &verbose       , "v" , false , "verbose" )
+    command_usage = split_usage_text
+    flags.Usage   = usage
+    flags.Parse( args )
+
+    infile   := flags.Arg( 0 )
+    template := flags.Arg( 1 )
+
+    if ( infile == "" ) {
+        usage()
+    }
+
+    if ( template == "" ) {
+        template = split_template
+    }
+
+    if ( ( opt.Channel < 0 ) || ( opt.Channel > 16 ) ) {
+        usage_msg( fmt.Sprintf( "ERROR: MIDI channel must be 1-16, not %d" , opt.Channel ) )
+    }
+
+    ////////////////////////////////////////
+    // Figure out the output file type. Default is SYX, since that's what
+    // synths can load.
+
+    var out_codec dx7.Codec
+
+    if ( otype != "" ) {
+        out_codec = dx7.CodecByName( otype )
+        if ( ( out_codec == nil ) || !out_codec.CanEncode() ) {
+            usage_msg( fmt.Sprintf( "ERROR: unable to write '%s' files" , otype ) )
+        }
+    } else {
+        out_codec = dx7.CodecForFile( template )
+        if ( ( out_codec == nil ) || !out_codec.CanEncode() ) {
+            out_codec = dx7.SYXCodec
+        }
+    }
+
+    ////////////////////////////////////////
+    // Read the input file
+
+    bank := read_input( infile , itype , verbose , lenient )
+
+    ////////////////////////////////////////
+    // Figure out every filename first, so that nothing is written if there
+    // are any collisions.
+
+    names , problems , err := split_names( template , bank , overwrite )
+    if ( err != nil ) {
+        usage_msg( fmt.Sprintf( "ERROR: %s" , err ) )
+    }
+
+    for _ , p := range problems {
+        fmt.Fprintf( os.Stderr , "ERROR: %s\n" , p )
+    }
+
+    if ( len( problems ) > 0 ) {
+        os.Exit( EXIT_USAGE )
+    }
+
+    ////////////////////////////////////////
+    // Write the files
+
+    err = split_write( bank , names , out_codec , opt , verbose )
+    if ( err != nil ) {
+        fail( err )
+    }
+}
@@ -0,0 +1,134 @@
+// volca-convert - split_test.go
+// John Simpson <jms1@jms1.net> 2022-10-01
+
+package main
+
+import (
+    "fmt"
+    "path/filepath"
+    "reflect"
+    "testing"
+
+    "jms1.net/volca-convert/dx7"
+)
+
+////////////////////////////////////////
+// Build a bank whose voices have the given names. The names are padded to
+// 10 characters, the same as they are after reading a SYX file.
+
+func split_bank( names ...string ) dx7.Bank {
+    var bank dx7.Bank
+
+    for n , name := range names {
+        v := dx7.InitVoice()
+        v.Name = fmt.Sprintf( "%-10s" , name )
+        v.ALGO = byte( n )
+        bank.Voices = append( bank.Voices , v )
+    }
+
+    return bank
+}
+
+func TestSplitFilename( t *testing.T ) {
+    tests := []struct {
+        template    string
+        num         int
+        name        string
+        want        string
+    }{
+        { "%02d-{name}.syx"     , 3  , "E.PIANO 1 " , "03-E_PIANO_1.syx" } ,
+        { "%d.json"             , 12 , "BASS"       , "12.json" } ,
+        { "out/{name}.syx"      , 1  , "brass/2"    , "out/brass_2.syx" } ,
+        { "{name}-{name}.syx"   , 1  , "A B"        , "A_B-A_B.syx" } ,
+        { "%03d-{name}.syx"     , 7  , "          " , "007-UNNAMED.syx" } ,
+        { "voice.syx"           , 1  , "X"          , "voice.syx" } ,
+    }
+
+    for _ , test := range tests {
+        v := dx7.InitVoice()
+        v.Name = test.name
+
+        got , err := split_filename( test.template , test.num , &v )
+        if ( err != nil ) {
+            t.Errorf( "%s: %v" , test.template , err )
+        } else if ( got != test.want ) {
+            t.Errorf( "%s: got %q, expected %q" , test.template , got , test.want )
+        }
+    }
+
+    ////////////////////////////////////////
+    // Templates which can't be used
+
+    v := dx7.InitVoice()
+    for _ , template := range []string{ "%d-%d.syx" , "%s.syx" } {
+        if _ , err := split_filename( template , 1 , &v ) ; err == nil {
+            t.Errorf( "%s: expected an error" , template )
+        }
+    }
+}
+
+func TestSplit( t *testing.T ) {
+    dir  := t.TempDir()
+    bank := split_bank( "ONE" , "TWO" , "ONE" )
+
+    ////////////////////////////////////////
+    // Numbered names are all different
+
+    template := filepath.Join( dir , "%02d-{name}.syx" )
+
+    names , problems , err := split_names( template , bank , false )
+    if ( ( err != nil ) || ( len( problems ) > 0 ) ) {
+        t.Fatalf( "unexpected problems: %v %v" , err , problems )
+    }
+
+    want := []string{
+        filepath.Join( dir , "01-ONE.syx" ) ,
+        filepath.Join( dir , "02-TWO.syx" ) ,
+        filepath.Join( dir , "03-ONE.syx" ) ,
+    }
+    if ( !reflect.DeepEqual( names , want ) ) {
+        t.Fatalf( "got %v, expected %v" , names , want )
+    }
+
+    ////////////////////////////////////////
+    // Each file holds one voice
+
+    err = split_write( bank , names , dx7.SYXCodec , dx7.Options{} , false )
+    if ( err != nil ) {
+        t.Fatal( err )
+    }
+
+    for n , name := range names {
+        got , err := dx7.ReadSYX( name )
+        if ( err != nil ) {
+            t.Fatal( err )
+        }
+
+        if ( ( len( got.Voices ) != 1 ) || ( got.Voices[0] != bank.Voices[n] ) ) {
+            t.Errorf( "%s: doesn't contain voice %d" , name , n + 1 )
+        }
+    }
+
+    ////////////////////////////////////////
+    // The files exist now, so they can only be written with "overwrite"
+
+    _ , problems , _ = split_names( template , bank , false )
+    if ( len( problems ) != 3 ) {
+        t.Errorf( "expected 3 problems, got %v" , problems )
+    } else if ( problems[1] != "voice 2: \"" + want[1] + "\" already exists (use --overwrite)" ) {
+        t.Errorf( "got %q" , problems[1] )
+    }
+
+    if _ , problems , _ = split_names( template , bank , true ) ; len( problems ) > 0 {
+        t.Errorf( "with overwrite: unexpected problems: %v" , problems )
+    }
+
+    ////////////////////////////////////////
+    // Names without the number collide
+
+    _ , problems , _ = split_names( filepath.Join( dir , "{name}.syx" ) , bank , false )
+    collide := "voices 1 and 3 would both be written to \"" + filepath.Join( dir , "ONE.syx" ) + "\""
+    if ( !reflect.DeepEqual( problems , []string{ collide } ) ) {
+        t.Errorf( "got %q, expected %q" , problems , collide )
+    }
+}